@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"path/filepath"
+	"runtime"
 
 	"github.com/fatih/color"
 	"github.com/m4rc3l05/dots/src"
@@ -26,10 +27,22 @@ func handlePanic(logger core.ILogger) {
 	}
 }
 
+func flagValueOrNil(name string, value *string) *string {
+	var result *string
+
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == name {
+			result = value
+		}
+	})
+
+	return result
+}
+
 func main() {
 	logger := core.MakeLogger()
 	displays := displays.Displays{Logger: logger}
-	commands := commands.Commands{Logger: logger}
+	commands := commands.Commands{Logger: logger, Input: os.Stdin}
 
 	defer handlePanic(logger)
 
@@ -49,6 +62,7 @@ func main() {
 
 	flag.CommandLine.SetOutput(os.Stdout)
 
+	targetDirFlag := flag.String("targetDir", homedir, "Set target dir")
 	dotfilesFilesDirFlag := flag.String(
 		"dotfilesFilesDir",
 		dotfilesFilesDirFallback,
@@ -58,6 +72,15 @@ func main() {
 	versionFlag := flag.Bool("version", false, "Show version")
 	printEnvironmentFlag := flag.Bool("printEnv", false, "Show environment")
 	colorFlag := flag.Bool("color", true, "Print with color")
+	dryRunFlag := flag.Bool("dryRun", false, "Print planned changes without applying them")
+	modeFlag := flag.String("mode", string(core.DeployModeCopy), "Deployment mode, copy or link")
+	profileFlag := flag.String("profile", "", "Comma separated list of profiles to activate")
+	identityFlag := flag.String("identity", "", "Set age identity file used for encrypted files")
+	outputFlag := flag.String("output", string(core.OutputFormatText), "Output format, text, json or ndjson")
+	forceFlag := flag.Bool("force", false, "Replace regular files with links in link mode")
+	pruneFlag := flag.Bool("prune", false, "Remove files previously applied that no longer exist in the dotfiles files")
+	interactiveFlag := flag.Bool("interactive", false, "Ask before applying or adopting each changed file")
+	depthFlag := flag.Int("depth", 3, "Max directory depth walked by unmanaged")
 
 	flag.Usage = func() {
 		displays.Help()
@@ -65,14 +88,109 @@ func main() {
 
 	flag.Parse()
 
-	dotfilesFilesDir, err := core.ResolveDotfilesFilesDir(dotfilesFilesDirFlag)
+	output, err := core.ParseOutputFormat(*outputFlag)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	if output != core.OutputFormatText {
+		logger = core.MakeLoggerTo(os.Stderr)
+		displays.Logger = logger
+		commands.Logger = logger
+		commands.Reporter = core.MakeReporter(output, os.Stdout)
+	}
+
+	color.NoColor = !*colorFlag || output != core.OutputFormatText
+
+	if *helpFlag {
+		displays.Help()
+
+		return
+	}
+
+	if *versionFlag {
+		displays.Version(Version)
+
+		return
+	}
+
+	settings, err := core.ResolveSettings(homedir, core.SettingsFlags{
+		DotfilesFilesDir: flagValueOrNil("dotfilesFilesDir", dotfilesFilesDirFlag),
+		TargetDir:        flagValueOrNil("targetDir", targetDirFlag),
+		Mode:             flagValueOrNil("mode", modeFlag),
+		Profile:          flagValueOrNil("profile", profileFlag),
+		Identity:         flagValueOrNil("identity", identityFlag),
+	})
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	dotfilesFilesDir, err := core.ResolveDotfilesFilesDir(&settings.DotfilesFilesDir.Value)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	hostname, _ := os.Hostname()
+
+	layers, err := core.ResolveLayers(
+		dotfilesFilesDir,
+		core.ParseProfiles(settings.Profiles.Value),
+		hostname,
+		runtime.GOOS,
+	)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	settings.Layers = layers
+
+	if *printEnvironmentFlag {
+		displays.Environment(settings)
+
+		return
+	}
+
+	hooks, err := core.ResolveHooks(
+		settings.Config.Hooks,
+		core.ResolveHooksDir(filepath.Dir(dotfilesFilesDir)),
+	)
 	if err != nil {
 		core.LogErrors(logger, err, 0)
 
 		os.Exit(1)
 	}
 
-	color.NoColor = !*colorFlag
+	recipients, err := core.ResolveRecipients(
+		settings.Config.Recipients,
+		core.ResolveRecipientsFile(filepath.Dir(dotfilesFilesDir)),
+	)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	targetDir, err := core.ResolveTargetDir(settings.TargetDir.Value)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
+
+	mode, err := core.ParseDeployMode(settings.Mode.Value)
+	if err != nil {
+		core.LogErrors(logger, err, 0)
+
+		os.Exit(1)
+	}
 
 	ok, err := src.App(src.Args{
 		CmdArgs: src.CmdArgs{
@@ -81,13 +199,26 @@ func main() {
 				Version:          *versionFlag,
 				Color:            *colorFlag,
 				PrintEnvironment: *printEnvironmentFlag,
+				DryRun:           *dryRunFlag,
+				Mode:             mode,
+				Force:            *forceFlag,
+				Interactive:      *interactiveFlag,
+				Prune:            *pruneFlag,
+				Depth:            *depthFlag,
 			},
 			Rest: flag.Args(),
 		},
 		Version:          Version,
 		Logger:           logger,
-		Homedir:          homedir,
+		Homedir:          targetDir,
 		DotfilesFilesDir: dotfilesFilesDir,
+		StateDir:         core.ResolveStateDir(homedir),
+		Settings:         settings,
+		Layers:           layers,
+		TemplateData:     core.ResolveTemplateData(homedir, settings.Config.Vars),
+		Hooks:            hooks,
+		Recipients:       recipients,
+		ScriptsDir:       core.ResolveScriptsDir(filepath.Dir(dotfilesFilesDir)),
 		Displays:         displays,
 		Commands:         commands,
 	})
@@ -95,6 +226,12 @@ func main() {
 		core.LogErrors(logger, err, 0)
 	}
 
+	if commands.Reporter != nil {
+		if err := commands.Reporter.Flush(); err != nil {
+			core.LogErrors(logger, err, 0)
+		}
+	}
+
 	if !ok {
 		os.Exit(1)
 	}
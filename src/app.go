@@ -1,6 +1,7 @@
 package src
 
 import (
+	"filippo.io/age"
 	"github.com/fatih/color"
 	"github.com/m4rc3l05/dots/src/commands"
 	"github.com/m4rc3l05/dots/src/core"
@@ -12,6 +13,12 @@ type CmdFlagsArgs struct {
 	Version          bool
 	Color            bool
 	PrintEnvironment bool
+	DryRun           bool
+	Mode             core.DeployMode
+	Force            bool
+	Interactive      bool
+	Prune            bool
+	Depth            int
 }
 
 type CmdArgs struct {
@@ -25,6 +32,13 @@ type Args struct {
 	Logger           core.ILogger
 	Homedir          string
 	DotfilesFilesDir string
+	StateDir         string
+	Settings         core.Settings
+	Layers           []core.Layer
+	TemplateData     core.TemplateData
+	Hooks            core.ConfigHooks
+	Recipients       []age.Recipient
+	ScriptsDir       string
 	Displays         displays.IDisplays
 	Commands         commands.ICommands
 }
@@ -37,6 +51,14 @@ func resolveForm(from []string, fallback string) string {
 	return from[1]
 }
 
+func resolveArg(rest []string, index int) string {
+	if len(rest) <= index {
+		return ""
+	}
+
+	return rest[index]
+}
+
 func resolveCmd(rest []string) string {
 	if len(rest) > 0 {
 		return rest[0]
@@ -45,6 +67,16 @@ func resolveCmd(rest []string) string {
 	return ""
 }
 
+func resolveMessage(rest []string) string {
+	for index, arg := range rest {
+		if (arg == "-m" || arg == "--message") && index+1 < len(rest) {
+			return rest[index+1]
+		}
+	}
+
+	return ""
+}
+
 func App(args Args) (bool, error) {
 	if args.CmdArgs.Flags.Help {
 		args.Displays.Help()
@@ -53,7 +85,7 @@ func App(args Args) (bool, error) {
 	}
 
 	if args.CmdArgs.Flags.PrintEnvironment {
-		args.Displays.Environment(args.Homedir, args.DotfilesFilesDir)
+		args.Displays.Environment(args.Settings)
 
 		return true, nil
 	}
@@ -70,18 +102,53 @@ func App(args Args) (bool, error) {
 	case "diff":
 		{
 			return args.Commands.Diff(commands.DiffArgs{
-				FromDir: args.DotfilesFilesDir,
-				ToDir:   args.Homedir,
+				FromDir:      args.DotfilesFilesDir,
+				ToDir:        args.Homedir,
+				Path:         resolveArg(args.CmdArgs.Rest, 1),
+				Mode:         args.CmdArgs.Flags.Mode,
+				Ignore:       args.Settings.Config.Ignore,
+				Layers:       args.Layers,
+				TemplateData: args.TemplateData,
+				Identity:     args.Settings.Identity.Value,
+				StateDir:     args.StateDir,
+			})
+		}
+
+	case "status":
+		{
+			return args.Commands.Status(commands.StatusArgs{
+				FromDir:      args.DotfilesFilesDir,
+				ToDir:        args.Homedir,
+				Path:         resolveArg(args.CmdArgs.Rest, 1),
+				Mode:         args.CmdArgs.Flags.Mode,
+				Ignore:       args.Settings.Config.Ignore,
+				Layers:       args.Layers,
+				TemplateData: args.TemplateData,
+				Identity:     args.Settings.Identity.Value,
+				StateDir:     args.StateDir,
 			})
 		}
 
 	case "apply":
 		{
 			return args.Commands.Apply(commands.ApplyArgs{
-				From: resolveForm(args.CmdArgs.Rest, args.DotfilesFilesDir),
+				From:        resolveForm(args.CmdArgs.Rest, args.DotfilesFilesDir),
+				DryRun:      args.CmdArgs.Flags.DryRun,
+				Mode:        args.CmdArgs.Flags.Mode,
+				Force:       args.CmdArgs.Flags.Force,
+				Interactive: args.CmdArgs.Flags.Interactive,
+				Prune:       args.CmdArgs.Flags.Prune,
 				Extra: commands.ApplyArgsExtra{
 					Homedir:          args.Homedir,
 					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+					TemplateData:     args.TemplateData,
+					Identity:         args.Settings.Identity.Value,
+					Hooks:            args.Hooks,
+					ScriptsDir:       args.ScriptsDir,
+					MergeTool:        args.Settings.MergeTool.Value,
 				},
 			})
 		}
@@ -89,13 +156,178 @@ func App(args Args) (bool, error) {
 	case "adopt":
 		{
 			return args.Commands.Adopt(commands.AdoptArgs{
-				From: resolveForm(args.CmdArgs.Rest, args.DotfilesFilesDir),
+				From:        resolveForm(args.CmdArgs.Rest, args.DotfilesFilesDir),
+				DryRun:      args.CmdArgs.Flags.DryRun,
+				Interactive: args.CmdArgs.Flags.Interactive,
+				Extra: commands.AdoptArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+					TemplateData:     args.TemplateData,
+					Identity:         args.Settings.Identity.Value,
+					Recipients:       args.Recipients,
+					Hooks:            args.Hooks,
+					MergeTool:        args.Settings.MergeTool.Value,
+				},
+			})
+		}
+
+	case "add":
+		{
+			return args.Commands.Add(commands.AddArgs{
+				Path:   resolveArg(args.CmdArgs.Rest, 1),
+				DryRun: args.CmdArgs.Flags.DryRun,
 				Extra: commands.AdoptArgsExtra{
 					Homedir:          args.Homedir,
 					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+					TemplateData:     args.TemplateData,
+					Identity:         args.Settings.Identity.Value,
+					Recipients:       args.Recipients,
+					Hooks:            args.Hooks,
+				},
+			})
+		}
+
+	case "forget":
+		{
+			return args.Commands.Forget(commands.ForgetArgs{
+				Path:   resolveArg(args.CmdArgs.Rest, 1),
+				DryRun: args.CmdArgs.Flags.DryRun,
+				Extra: commands.ForgetArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+				},
+			})
+		}
+
+	case "managed":
+		{
+			return args.Commands.Managed(commands.ManagedArgs{
+				Path: resolveArg(args.CmdArgs.Rest, 1),
+				Extra: commands.ManagedArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+				},
+			})
+		}
+
+	case "unmanaged":
+		{
+			return args.Commands.Unmanaged(commands.UnmanagedArgs{
+				Path:  resolveArg(args.CmdArgs.Rest, 1),
+				Depth: args.CmdArgs.Flags.Depth,
+				Extra: commands.UnmanagedArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+					Roots:            args.Settings.Config.UnmanagedRoots,
 				},
 			})
 		}
+
+	case "git":
+		{
+			return args.Commands.Git(commands.GitArgs{
+				Args:  args.CmdArgs.Rest[1:],
+				Extra: commands.GitArgsExtra{DotfilesFilesDir: args.DotfilesFilesDir},
+			})
+		}
+
+	case "commit":
+		{
+			return args.Commands.Commit(commands.CommitArgs{
+				Message: resolveMessage(args.CmdArgs.Rest[1:]),
+				Extra: commands.CommitArgsExtra{
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					Layers:           args.Layers,
+				},
+			})
+		}
+
+	case "sync":
+		{
+			return args.Commands.Sync(commands.SyncArgs{
+				Message: resolveMessage(args.CmdArgs.Rest[1:]),
+				Mode:    args.CmdArgs.Flags.Mode,
+				Extra: commands.ApplyArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+					TemplateData:     args.TemplateData,
+					Identity:         args.Settings.Identity.Value,
+					Recipients:       args.Recipients,
+					Hooks:            args.Hooks,
+					ScriptsDir:       args.ScriptsDir,
+					MergeTool:        args.Settings.MergeTool.Value,
+				},
+			})
+		}
+
+	case "restore":
+		{
+			return args.Commands.Restore(commands.RestoreArgs{
+				Backup: resolveArg(args.CmdArgs.Rest, 1),
+				Path:   resolveArg(args.CmdArgs.Rest, 2),
+				Extra: commands.RestoreArgsExtra{
+					Homedir:  args.Homedir,
+					StateDir: args.StateDir,
+				},
+			})
+		}
+
+	case "prune":
+		{
+			return args.Commands.Prune(commands.PruneArgs{
+				Path:   resolveArg(args.CmdArgs.Rest, 1),
+				DryRun: args.CmdArgs.Flags.DryRun,
+				Extra: commands.PruneArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					StateDir:         args.StateDir,
+					Layers:           args.Layers,
+				},
+			})
+		}
+
+	case "scripts":
+		{
+			return args.Commands.Scripts(commands.ScriptsArgs{
+				Action: resolveArg(args.CmdArgs.Rest, 1),
+				Name:   resolveArg(args.CmdArgs.Rest, 2),
+				Extra: commands.ScriptsArgsExtra{
+					StateDir:   args.StateDir,
+					ScriptsDir: args.ScriptsDir,
+				},
+			})
+		}
+
+	case "check-ignore":
+		{
+			return args.Commands.CheckIgnore(commands.CheckIgnoreArgs{
+				Path: resolveArg(args.CmdArgs.Rest, 1),
+				Extra: commands.CheckIgnoreArgsExtra{
+					Homedir:          args.Homedir,
+					DotfilesFilesDir: args.DotfilesFilesDir,
+					Ignore:           args.Settings.Config.Ignore,
+					Layers:           args.Layers,
+				},
+			})
+		}
+
 	default:
 		{
 			args.Displays.Help()
@@ -5,6 +5,7 @@ import (
 
 	"github.com/m4rc3l05/dots/src"
 	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
 	"github.com/m4rc3l05/dots/src/testing"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -39,12 +40,18 @@ var _ = Describe("app()", func() {
 	})
 
 	It("should print environment if `printEnvironment` flag is provided", func() {
+		settings := core.Settings{
+			Homedir:          "/home/foo",
+			DotfilesFilesDir: core.Setting{Value: "/foo", Source: core.SettingSourceFlag},
+		}
+
 		src.App(src.Args{
 			CmdArgs: src.CmdArgs{
 				Flags: src.CmdFlagsArgs{
 					PrintEnvironment: true,
 				},
 			},
+			Settings: settings,
 			Displays: displays,
 			Commands: cmds,
 			Logger:   logger,
@@ -53,6 +60,7 @@ var _ = Describe("app()", func() {
 		testing.AssertSpyLoggerCalls(*logger, nil)
 		testing.AssertSpyCommandsCalls(*cmds, nil)
 		testing.AssertSpyDisplaysCalls(*displays, &testing.SpyDisplaysCallNumber{Environment: 1})
+		Expect(displays.Calls.Environment[0].Args).To(Equal([]any{settings}))
 	})
 
 	It("should print version if `version` flag is provided", func() {
@@ -87,6 +95,165 @@ var _ = Describe("app()", func() {
 		testing.AssertSpyDisplaysCalls(*displays, nil)
 	})
 
+	It("should run diff with mode flag", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{
+					Mode: core.DeployModeLink,
+				},
+				Rest: []string{"diff"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Diff: 1})
+		Expect(
+			cmds.Calls.Diff[0].Args,
+		).To(Equal([]any{commands.DiffArgs{Mode: core.DeployModeLink}}))
+	})
+
+	It("should run diff with a path", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"diff", "foo"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Diff: 1})
+		Expect(
+			cmds.Calls.Diff[0].Args,
+		).To(Equal([]any{commands.DiffArgs{Path: "foo"}}))
+	})
+
+	It("should run status with a path", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"status", "foo"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Status: 1})
+		Expect(
+			cmds.Calls.Status[0].Args,
+		).To(Equal([]any{commands.StatusArgs{Path: "foo"}}))
+	})
+
+	It("should run apply and adopt interactively", func() {
+		for _, command := range []string{"apply", "adopt"} {
+			src.App(src.Args{
+				CmdArgs: src.CmdArgs{
+					Flags: src.CmdFlagsArgs{Interactive: true},
+					Rest:  []string{command},
+				},
+				Displays: displays,
+				Commands: cmds,
+				Logger:   logger,
+			})
+		}
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Apply: 1, Adopt: 1})
+		Expect(cmds.Calls.Apply[0].Args[0].(commands.ApplyArgs).Interactive).To(BeTrue())
+		Expect(cmds.Calls.Adopt[0].Args[0].(commands.AdoptArgs).Interactive).To(BeTrue())
+	})
+
+	It("should run prune and apply with prune", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{DryRun: true},
+				Rest:  []string{"prune", "foo"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{Prune: true},
+				Rest:  []string{"apply"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Prune: 1, Apply: 1})
+		Expect(
+			cmds.Calls.Prune[0].Args,
+		).To(Equal([]any{commands.PruneArgs{Path: "foo", DryRun: true}}))
+		Expect(cmds.Calls.Apply[0].Args[0].(commands.ApplyArgs).Prune).To(BeTrue())
+	})
+
+	It("should run add, forget and managed", func() {
+		for _, rest := range [][]string{{"add", "foo"}, {"forget", "bar"}, {"managed", "baz"}} {
+			src.App(src.Args{
+				CmdArgs: src.CmdArgs{
+					Flags: src.CmdFlagsArgs{DryRun: true},
+					Rest:  rest,
+				},
+				Displays: displays,
+				Commands: cmds,
+				Logger:   logger,
+			})
+		}
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Add: 1, Forget: 1, Managed: 1})
+		Expect(
+			cmds.Calls.Add[0].Args,
+		).To(Equal([]any{commands.AddArgs{Path: "foo", DryRun: true}}))
+		Expect(
+			cmds.Calls.Forget[0].Args,
+		).To(Equal([]any{commands.ForgetArgs{Path: "bar", DryRun: true}}))
+		Expect(cmds.Calls.Managed[0].Args).To(Equal([]any{commands.ManagedArgs{Path: "baz"}}))
+	})
+
+	It("should run unmanaged", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{Depth: 2},
+				Rest:  []string{"unmanaged", "foo"},
+			},
+			Settings: core.Settings{Config: core.Config{UnmanagedRoots: []string{".config"}}},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Unmanaged: 1})
+		Expect(cmds.Calls.Unmanaged[0].Args).To(Equal([]any{commands.UnmanagedArgs{
+			Path:  "foo",
+			Depth: 2,
+			Extra: commands.UnmanagedArgsExtra{Roots: []string{".config"}},
+		}}))
+	})
+
+	It("should run git, commit and sync", func() {
+		for _, rest := range [][]string{
+			{"git", "log", "-1"},
+			{"commit", "-m", "foo"},
+			{"sync", "--message", "bar"},
+		} {
+			src.App(src.Args{
+				CmdArgs:  src.CmdArgs{Rest: rest},
+				Displays: displays,
+				Commands: cmds,
+				Logger:   logger,
+			})
+		}
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Git: 1, Commit: 1, Sync: 1})
+		Expect(cmds.Calls.Git[0].Args).To(Equal([]any{commands.GitArgs{Args: []string{"log", "-1"}}}))
+		Expect(cmds.Calls.Commit[0].Args).To(Equal([]any{commands.CommitArgs{Message: "foo"}}))
+		Expect(cmds.Calls.Sync[0].Args).To(Equal([]any{commands.SyncArgs{Message: "bar"}}))
+	})
+
 	It("should return what `diff` returned", func() {
 		ok, err := src.App(src.Args{
 			CmdArgs: src.CmdArgs{
@@ -136,6 +303,45 @@ var _ = Describe("app()", func() {
 		Expect(cmds.Calls.Apply[0].Args).To(Equal([]any{commands.ApplyArgs{From: "foo"}}))
 	})
 
+	It("should run apply in dry run mode if `dryRun` flag is provided", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{
+					DryRun: true,
+				},
+				Rest: []string{"apply"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyLoggerCalls(*logger, nil)
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Apply: 1})
+		testing.AssertSpyDisplaysCalls(*displays, nil)
+		Expect(cmds.Calls.Apply[0].Args).To(Equal([]any{commands.ApplyArgs{DryRun: true}}))
+	})
+
+	It("should run apply with mode and force flags", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{
+					Mode:  core.DeployModeLink,
+					Force: true,
+				},
+				Rest: []string{"apply"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Apply: 1})
+		Expect(
+			cmds.Calls.Apply[0].Args,
+		).To(Equal([]any{commands.ApplyArgs{Mode: core.DeployModeLink, Force: true}}))
+	})
+
 	It("should return what `apply` returned", func() {
 		ok, err := src.App(src.Args{
 			CmdArgs: src.CmdArgs{
@@ -185,6 +391,25 @@ var _ = Describe("app()", func() {
 		Expect(cmds.Calls.Adopt[0].Args).To(Equal([]any{commands.AdoptArgs{From: "foo"}}))
 	})
 
+	It("should run adopt in dry run mode if `dryRun` flag is provided", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Flags: src.CmdFlagsArgs{
+					DryRun: true,
+				},
+				Rest: []string{"adopt"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyLoggerCalls(*logger, nil)
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Adopt: 1})
+		testing.AssertSpyDisplaysCalls(*displays, nil)
+		Expect(cmds.Calls.Adopt[0].Args).To(Equal([]any{commands.AdoptArgs{DryRun: true}}))
+	})
+
 	It("should return what `adopt` returned", func() {
 		ok, err := src.App(src.Args{
 			CmdArgs: src.CmdArgs{
@@ -203,6 +428,177 @@ var _ = Describe("app()", func() {
 		Expect(err).To(MatchError("foo"))
 	})
 
+	It("should pass config ignore patterns to commands", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"diff"},
+			},
+			Settings: core.Settings{Config: core.Config{Ignore: []string{"*.swp"}}},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Diff: 1})
+		Expect(
+			cmds.Calls.Diff[0].Args,
+		).To(Equal([]any{commands.DiffArgs{Ignore: []string{"*.swp"}}}))
+	})
+
+	It("should pass layers to commands", func() {
+		layers := []core.Layer{{Name: "base", Dir: "/foo"}, {Name: "profiles/bar", Dir: "/profiles/bar"}}
+
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"apply"},
+			},
+			DotfilesFilesDir: "/foo",
+			Layers:           layers,
+			Displays:         displays,
+			Commands:         cmds,
+			Logger:           logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Apply: 1})
+		Expect(cmds.Calls.Apply[0].Args).To(Equal([]any{commands.ApplyArgs{
+			From:  "/foo",
+			Extra: commands.ApplyArgsExtra{DotfilesFilesDir: "/foo", Layers: layers},
+		}}))
+	})
+
+	It("should pass template data to commands", func() {
+		data := core.TemplateData{Hostname: "foo", Vars: map[string]any{"bar": "baz"}}
+
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"diff"},
+			},
+			TemplateData: data,
+			Displays:     displays,
+			Commands:     cmds,
+			Logger:       logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Diff: 1})
+		Expect(cmds.Calls.Diff[0].Args).To(Equal([]any{commands.DiffArgs{TemplateData: data}}))
+	})
+
+	It("should pass the identity file to commands", func() {
+		settings := core.Settings{Identity: core.Setting{Value: "/foo/key.txt"}}
+
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"adopt"},
+			},
+			DotfilesFilesDir: "/foo",
+			Settings:         settings,
+			Displays:         displays,
+			Commands:         cmds,
+			Logger:           logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Adopt: 1})
+		Expect(cmds.Calls.Adopt[0].Args).To(Equal([]any{commands.AdoptArgs{
+			From:  "/foo",
+			Extra: commands.AdoptArgsExtra{DotfilesFilesDir: "/foo", Identity: "/foo/key.txt"},
+		}}))
+	})
+
+	It("should pass hooks to apply", func() {
+		hooks := core.ConfigHooks{PostApply: []core.ConfigHook{{Command: "echo foo"}}}
+
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"apply"},
+			},
+			DotfilesFilesDir: "/foo",
+			Hooks:            hooks,
+			Displays:         displays,
+			Commands:         cmds,
+			Logger:           logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Apply: 1})
+		Expect(cmds.Calls.Apply[0].Args).To(Equal([]any{commands.ApplyArgs{
+			From:  "/foo",
+			Extra: commands.ApplyArgsExtra{DotfilesFilesDir: "/foo", Hooks: hooks},
+		}}))
+	})
+
+	It("should run scripts with an action and name if `scripts` command provided", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"scripts", "reset", "foo"},
+			},
+			StateDir:   "/state",
+			ScriptsDir: "/scripts",
+			Displays:   displays,
+			Commands:   cmds,
+			Logger:     logger,
+		})
+
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Scripts: 1})
+		Expect(cmds.Calls.Scripts[0].Args).To(Equal([]any{commands.ScriptsArgs{
+			Action: "reset",
+			Name:   "foo",
+			Extra:  commands.ScriptsArgsExtra{StateDir: "/state", ScriptsDir: "/scripts"},
+		}}))
+	})
+
+	It("should run check-ignore with a path if `check-ignore` command provided", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"check-ignore", "foo"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyLoggerCalls(*logger, nil)
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{CheckIgnore: 1})
+		testing.AssertSpyDisplaysCalls(*displays, nil)
+		Expect(
+			cmds.Calls.CheckIgnore[0].Args,
+		).To(Equal([]any{commands.CheckIgnoreArgs{Path: "foo"}}))
+	})
+
+	It("should run restore with backup and path args if `restore` command provided", func() {
+		src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"restore", "foo", "bar"},
+			},
+			Displays: displays,
+			Commands: cmds,
+			Logger:   logger,
+		})
+
+		testing.AssertSpyLoggerCalls(*logger, nil)
+		testing.AssertSpyCommandsCalls(*cmds, &testing.SpyCommandsCallNumber{Restore: 1})
+		testing.AssertSpyDisplaysCalls(*displays, nil)
+		Expect(
+			cmds.Calls.Restore[0].Args,
+		).To(Equal([]any{commands.RestoreArgs{Backup: "foo", Path: "bar"}}))
+	})
+
+	It("should return what `restore` returned", func() {
+		ok, err := src.App(src.Args{
+			CmdArgs: src.CmdArgs{
+				Rest: []string{"restore"},
+			},
+			Displays: displays,
+			Commands: &testing.SpyCommands{
+				Impl: testing.SpyCommandsImpl{
+					Restore: func(args commands.RestoreArgs) (bool, error) { return false, errors.New("foo") },
+				},
+			},
+			Logger: logger,
+		})
+
+		Expect(ok).To(BeFalse())
+		Expect(err).To(MatchError("foo"))
+	})
+
 	It("should print help if command is not supported", func() {
 		src.App(src.Args{
 			CmdArgs: src.CmdArgs{
@@ -0,0 +1,44 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type AddArgs struct {
+	Path   string
+	DryRun bool
+	Extra  AdoptArgsExtra
+}
+
+func (c Commands) Add(args AddArgs) (bool, error) {
+	if args.Path == "" {
+		return false, errors.New("a path must be provided")
+	}
+
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	path, err := resolveAdoptFrom(args.Path, args.Extra.Homedir, layers)
+	if err != nil {
+		return false, err
+	}
+
+	if path == layers[0].Dir {
+		return false, fmt.Errorf(
+			"path %s is not a subpath of %s",
+			color.BlueString(path), color.BlueString(args.Extra.Homedir),
+		)
+	}
+
+	if layer, ok := core.FindOwnerLayer(layers, relativeTo(args.Extra.Homedir, path)); ok {
+		return false, fmt.Errorf(
+			"path %s is already managed in %s, use adopt to update it",
+			color.BlueString(path), color.BlueString(layer.Dir),
+		)
+	}
+
+	return c.adopt(AdoptArgs{From: path, DryRun: args.DryRun, Extra: args.Extra}, addMessages)
+}
@@ -0,0 +1,131 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("add", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var reporter *testing.SpyReporter
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir = filepath.Join(workingDir, "state")
+		logger = testing.MakeSpyLogger()
+		reporter = testing.MakeSpyReporter()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger, Reporter: reporter}
+
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "2"), []byte("bar"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	add := func(path string, dryRun bool) (bool, error) {
+		return cmd.Add(commands.AddArgs{
+			Path:   path,
+			DryRun: dryRun,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+	}
+
+	It("should copy a new directory into the dotfiles files and record it", func() {
+		result, err := add(filepath.Join(homedir, ".config", "foo"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"))).To(Equal([]byte("foo")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "2"))).To(Equal([]byte("bar")))
+		Expect(reporter.Records).To(HaveLen(2))
+		Expect(reporter.Records[0].Command).To(Equal("add"))
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state).To(HaveKey(filepath.Join(".config", "foo", "1")))
+		Expect(state).To(HaveKey(filepath.Join(".config", "foo", "2")))
+	})
+
+	It("should copy a single file", func() {
+		result, err := add(filepath.Join(homedir, ".config", "foo", "1"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"))).To(BeTrue())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "foo", "2"))).To(BeFalse())
+	})
+
+	It("should not touch the filesystem on dry run", func() {
+		result, err := add(filepath.Join(homedir, ".config", "foo"), true)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config"))).To(BeFalse())
+	})
+
+	It("should return an error if the path is already managed", func() {
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"), []byte("bar"), 0o600)
+
+		for _, path := range []string{
+			filepath.Join(homedir, ".config", "foo"),
+			filepath.Join(homedir, ".config", "foo", "1"),
+		} {
+			result, err := add(path, false)
+
+			Expect(result).To(BeFalse())
+			Expect(err).To(MatchError(
+				"path " + path + " is already managed in " + dotfilesFilesDir + ", use adopt to update it",
+			))
+		}
+
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"))).To(Equal([]byte("bar")))
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "foo", "2"))).To(BeFalse())
+	})
+
+	It("should return an error if no path is provided", func() {
+		result, err := add("", false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("a path must be provided"))
+	})
+
+	It("should return an error if the path is the dotfiles files directory", func() {
+		result, err := add(dotfilesFilesDir, false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"path " + dotfilesFilesDir + " is not a subpath of " + homedir,
+		))
+	})
+
+	It("should return an error if the path is not under ~/", func() {
+		result, err := add(workingDir, false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("path " + workingDir + " is not a subpath of " + homedir))
+	})
+})
@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/fatih/color"
 	"github.com/gookit/goutil/fsutil"
 	"github.com/m4rc3l05/dots/src/core"
@@ -15,120 +17,343 @@ import (
 type AdoptArgsExtra struct {
 	Homedir          string
 	DotfilesFilesDir string
+	StateDir         string
+	Ignore           []string
+	Layers           []core.Layer
+	TemplateData     core.TemplateData
+	Identity         string
+	Recipients       []age.Recipient
+	Hooks            core.ConfigHooks
+	MergeTool        string
 }
 
 type AdoptArgs struct {
-	From  string
-	Extra AdoptArgsExtra
+	From        string
+	DryRun      bool
+	Interactive bool
+	Extra       AdoptArgsExtra
 }
 
-func (c Commands) Adopt(args AdoptArgs) (bool, error) {
-	fromFormatted, err := filepath.Abs(args.From)
+func resolveAdoptFrom(from string, homedir string, layers []core.Layer) (string, error) {
+	fromFormatted, err := filepath.Abs(from)
 	if err != nil {
-		return false, err
+		return "", err
 	}
 
-	args.From = fromFormatted
-
-	if !fsutil.PathExist(args.From) || !core.IsPathReadable(args.From) {
-		return false, fmt.Errorf(
+	if (!fsutil.PathExist(fromFormatted) || !core.IsPathReadable(fromFormatted)) &&
+		!core.IsSymlink(fromFormatted) {
+		return "", fmt.Errorf(
 			"path %s does not exists or is not readable",
-			color.BlueString(args.From),
+			color.BlueString(fromFormatted),
 		)
 	}
 
-	if args.From != args.Extra.DotfilesFilesDir {
-		if !strings.HasPrefix(args.From, args.Extra.Homedir) {
-			return false, fmt.Errorf(
+	if fromFormatted != layers[0].Dir {
+		if !strings.HasPrefix(fromFormatted, homedir) {
+			return "", fmt.Errorf(
 				"path %s is not a subpath of %s",
-				color.BlueString(args.From), color.BlueString(args.Extra.Homedir),
+				color.BlueString(fromFormatted), color.BlueString(homedir),
 			)
 		}
 
-		if strings.HasPrefix(args.From, args.Extra.DotfilesFilesDir) {
-			return false, fmt.Errorf(
+		if layer, ok := core.FindLayer(layers, fromFormatted); ok {
+			return "", fmt.Errorf(
 				"path %s can not be a subpath of %s",
-				color.BlueString(args.From), color.BlueString(args.Extra.DotfilesFilesDir),
+				color.BlueString(fromFormatted), color.BlueString(layer.Dir),
 			)
 		}
 	}
 
-	if fsutil.IsFile(args.From) {
-		to := strings.Replace(args.From, args.Extra.Homedir, args.Extra.DotfilesFilesDir, 1)
+	return fromFormatted, nil
+}
 
-		c.Logger.Log(
-			"Adopting %s to %s ...",
-			color.BlueString(args.From),
-			color.BlueString(to),
+func planAdoptTemplate(origin string, template string, args AdoptArgs) (PlanEntry, error) {
+	content, err := core.RenderTemplate(template, args.Extra.TemplateData)
+	if err != nil {
+		return PlanEntry{}, errors.Join(
+			fmt.Errorf("error rendering template %s", color.BlueString(template)),
+			err,
 		)
+	}
 
-		if err := core.RecreateFile(args.From, to); err != nil {
-			c.Logger.Lognl(color.GreenString(" ✕"))
+	entry := PlanEntry{
+		Action:   PlanActionBlocked,
+		From:     origin,
+		To:       template,
+		Template: true,
+		Content:  content,
+	}
 
-			return false, errors.Join(fmt.Errorf(
-				"error adopting %s to %s",
-				color.BlueString(args.From),
-				color.BlueString(to),
-			), err)
-		}
+	if core.ContentEqual(origin, content) {
+		entry.Action = PlanActionUnchanged
+	}
 
-		c.Logger.Lognl(color.GreenString(" ✓"))
+	return entry, nil
+}
 
-		return true, nil
+func planAdoptEncrypted(origin string, encrypted string, args AdoptArgs) (PlanEntry, error) {
+	current, err := core.DecryptFile(encrypted, args.Extra.Identity)
+	if err != nil {
+		return PlanEntry{}, errors.Join(
+			fmt.Errorf("error decrypting %s", color.BlueString(encrypted)),
+			err,
+		)
+	}
+
+	entry := PlanEntry{Action: PlanActionOverwrite, From: origin, To: encrypted, Encrypted: true}
+
+	if core.ContentEqual(origin, current) {
+		entry.Action = PlanActionUnchanged
+
+		return entry, nil
+	}
+
+	content, err := os.ReadFile(origin)
+	if err != nil {
+		return PlanEntry{}, err
+	}
+
+	entry.Content, err = core.Encrypt(content, args.Extra.Identity, args.Extra.Recipients)
+	if err != nil {
+		return PlanEntry{}, errors.Join(
+			fmt.Errorf("error encrypting %s", color.BlueString(origin)),
+			err,
+		)
+	}
+
+	return entry, nil
+}
+
+func planAdoptPath(origin string, destination string, layer core.Layer, args AdoptArgs) (PlanEntry, error) {
+	template := destination + core.TemplateSuffix
+
+	if _, err := os.Lstat(template); err == nil {
+		return planAdoptTemplate(origin, template, args)
 	}
 
-	var errorsArr []error
+	encrypted := destination + core.EncryptedSuffix
 
-	err = filepath.WalkDir(args.From, func(path string, d fs.DirEntry, err error) error {
+	if _, err := os.Lstat(encrypted); err == nil {
+		return planAdoptEncrypted(origin, encrypted, args)
+	}
+
+	return planPath(origin, destination, args.Extra.Homedir, layer.Dir), nil
+}
+
+func planAdoptLayer(
+	layer core.Layer,
+	layers []core.Layer,
+	ignore core.IgnoreMatcher,
+	args AdoptArgs,
+	add func(entry PlanEntry),
+) error {
+	return filepath.WalkDir(layer.Dir, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
 			return err
 		}
 
-		if !d.Type().IsRegular() {
+		if isOtherLayerDir(layers, layer, path) {
+			return filepath.SkipDir
+		}
+
+		if isPathIgnored(ignore, layer.Dir, path, d.IsDir()) {
+			return skipIgnored(d)
+		}
+
+		if !isPlannable(d) {
+			return nil
+		}
+
+		if isShadowedBySource(path) {
 			return nil
 		}
 
-		var origin string
-		var destination string
+		relative := core.SourceTarget(relativeTo(layer.Dir, path))
 
-		if strings.HasPrefix(path, args.Extra.DotfilesFilesDir) {
-			origin = strings.Replace(path, args.Extra.DotfilesFilesDir, args.Extra.Homedir, 1)
-			destination = path
-		} else {
-			origin = path
-			destination = strings.Replace(path, args.Extra.Homedir, args.Extra.DotfilesFilesDir, 1)
+		if owner := resolveDestinationLayer(layers, relative); owner.Dir != layer.Dir {
+			return nil
 		}
 
-		c.Logger.Log(
-			"Adopting %s to %s ...",
-			color.BlueString(origin),
-			color.BlueString(destination),
+		entry, err := planAdoptPath(
+			filepath.Join(args.Extra.Homedir, relative),
+			filepath.Join(layer.Dir, relative),
+			layer,
+			args,
 		)
+		if err != nil {
+			return err
+		}
 
-		if err := core.RecreateFile(origin, destination); err != nil {
-			c.Logger.Lognl(color.RedString(" ✕"))
+		add(entry)
 
-			errorsArr = append(
-				errorsArr,
-				errors.Join(fmt.Errorf(
-					"error adopting %s to %s",
-					color.BlueString(origin),
-					color.BlueString(destination),
-				), err),
-			)
-		} else {
-			c.Logger.Lognl(color.GreenString(" ✓"))
+		return nil
+	})
+}
+
+func planAdoptHome(
+	layers []core.Layer,
+	ignores map[string]core.IgnoreMatcher,
+	args AdoptArgs,
+	add func(entry PlanEntry),
+) error {
+	return filepath.WalkDir(args.From, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if _, ok := core.FindLayer(layers, path); ok {
+			return skipIgnored(d)
+		}
+
+		relative := relativeTo(args.Extra.Homedir, path)
+		layer := resolveDestinationLayer(layers, relative)
+		destination := filepath.Join(layer.Dir, relative)
+
+		if isPathIgnored(ignores[layer.Dir], layer.Dir, destination, d.IsDir()) {
+			return skipIgnored(d)
+		}
+
+		if !isPlannable(d) {
+			return nil
+		}
+
+		entry, err := planAdoptPath(path, destination, layer, args)
+		if err != nil {
+			return err
 		}
 
+		add(entry)
+
 		return nil
 	})
+}
+
+func PlanAdopt(args AdoptArgs) (Plan, error) {
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	from, err := resolveAdoptFrom(args.From, args.Extra.Homedir, layers)
 	if err != nil {
-		errorsArr = append(errorsArr, err)
+		return Plan{}, err
 	}
 
-	if len(errorsArr) > 0 {
-		errorsArr = append([]error{errors.New("error adopting directory")}, errorsArr...)
+	args.From = from
+
+	ignores, err := loadLayersIgnoreMatchers(layers, args.Extra.Ignore)
+	if err != nil {
+		return Plan{}, err
 	}
 
-	return len(errorsArr) <= 0, errors.Join(errorsArr...)
+	if args.From != layers[0].Dir {
+		relative := relativeTo(args.Extra.Homedir, args.From)
+		layer := resolveDestinationLayer(layers, relative)
+		destination := filepath.Join(layer.Dir, relative)
+
+		if isPathIgnored(ignores[layer.Dir], layer.Dir, destination, fsutil.IsDir(args.From)) {
+			return Plan{}, fmt.Errorf("path %s is ignored", color.BlueString(args.From))
+		}
+
+		if fsutil.IsFile(args.From) || core.IsSymlink(args.From) {
+			entry, err := planAdoptPath(args.From, destination, layer, args)
+			if err != nil {
+				return Plan{}, err
+			}
+
+			return Plan{Entries: []PlanEntry{entry}}, nil
+		}
+	}
+
+	plan := Plan{IsDir: true}
+
+	add := func(entry PlanEntry) {
+		plan.Entries = append(plan.Entries, entry)
+	}
+
+	if args.From == layers[0].Dir {
+		for _, layer := range layers {
+			if err := planAdoptLayer(layer, layers, ignores[layer.Dir], args, add); err != nil {
+				return Plan{}, errors.Join(errors.New("error planning directory"), err)
+			}
+		}
+	} else if err := planAdoptHome(layers, ignores, args, add); err != nil {
+		return Plan{}, errors.Join(errors.New("error planning directory"), err)
+	}
+
+	return plan, nil
+}
+
+func (c Commands) Adopt(args AdoptArgs) (bool, error) {
+	return c.adopt(args, adoptMessages)
+}
+
+func (c Commands) adopt(args AdoptArgs, messages planMessages) (bool, error) {
+	plan, err := PlanAdopt(args)
+	if err != nil {
+		return false, err
+	}
+
+	ctx := hooksContext{
+		command:          messages.command,
+		homedir:          args.Extra.Homedir,
+		dotfilesFilesDir: args.Extra.DotfilesFilesDir,
+		home:             func(entry PlanEntry) string { return entry.From },
+	}
+
+	if args.DryRun {
+		c.logPlan(plan, messages)
+		c.logHooks(core.HookStagePreAdopt, args.Extra.Hooks.PreAdopt, ctx.changedPaths(plan.Entries))
+		c.logHooks(core.HookStagePostAdopt, args.Extra.Hooks.PostAdopt, ctx.changedPaths(plan.Entries))
+
+		return true, nil
+	}
+
+	var reversed Plan
+
+	if args.Interactive {
+		plan, reversed, err = c.reviewPlan(plan, messages, applyMessages, reviewMerge{
+			ctx:       ctx,
+			stateDir:  args.Extra.StateDir,
+			mergeTool: args.Extra.MergeTool,
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if err := c.runHooks(core.HookStagePreAdopt, args.Extra.Hooks.PreAdopt, plan.Entries, ctx); err != nil {
+		return false, err
+	}
+
+	if len(reversed.Entries) > 0 && args.Extra.StateDir != "" {
+		if err := c.backupPlan(reversed, args.Extra.Homedir, args.Extra.StateDir); err != nil {
+			return false, err
+		}
+	}
+
+	changed, err := c.executePlan(plan, messages)
+
+	var reversedErr error
+	var reversedStateErr error
+
+	if len(reversed.Entries) > 0 {
+		var reversedChanged []PlanEntry
+
+		reversedChanged, reversedErr = c.executePlan(reversed, applyMessages)
+		reversedStateErr = recordState(reversedChanged, hooksContext{
+			command:          applyMessages.command,
+			homedir:          args.Extra.Homedir,
+			dotfilesFilesDir: args.Extra.DotfilesFilesDir,
+			home:             func(entry PlanEntry) string { return entry.To },
+		}, args.Extra.StateDir)
+	}
+
+	stateErr := recordState(append(unchangedEntries(plan), changed...), ctx, args.Extra.StateDir)
+
+	return joinPlanErrors(
+		messages,
+		err,
+		reversedErr,
+		reversedStateErr,
+		stateErr,
+		c.runHooks(core.HookStagePostAdopt, args.Extra.Hooks.PostAdopt, changed, ctx),
+	)
 }
@@ -3,8 +3,10 @@ package commands_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/fatih/color"
 	"github.com/gookit/goutil/fsutil"
 	"github.com/m4rc3l05/dots/src/commands"
@@ -134,7 +136,8 @@ var _ = Describe("adopt()", func() {
 
 		Expect(result).To(BeTrue())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 1, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", from.Name(), strings.Replace(from.Name(), homedir, dotfilesFilesDir, 1)}))
@@ -150,6 +153,7 @@ var _ = Describe("adopt()", func() {
 		toF, _ := os.Create(to)
 		defer toF.Close()
 
+		from.WriteString("foo")
 		os.Chmod(to, 0o400)
 
 		result, err := cmd.Adopt(commands.AdoptArgs{
@@ -168,7 +172,8 @@ var _ = Describe("adopt()", func() {
 		Expect(
 			unwrapErrors.Unwrap()[1],
 		).To(MatchError(fmt.Sprintf("open %s: permission denied", to)))
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 0, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", from.Name(), strings.Replace(from.Name(), homedir, dotfilesFilesDir, 1)}))
@@ -183,8 +188,8 @@ var _ = Describe("adopt()", func() {
 		f2, _ := os.CreateTemp(homedir, "2-*")
 		defer f2.Close()
 
-		fsutil.CopyFile(f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1))
-		fsutil.CopyFile(f2.Name(), strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1))
+		core.RecreateFile(f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1))
+		core.RecreateFile(f2.Name(), strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1))
 
 		result, err := cmd.Adopt(commands.AdoptArgs{
 			From: dotfilesFilesDir,
@@ -196,15 +201,16 @@ var _ = Describe("adopt()", func() {
 
 		Expect(result).To(BeTrue())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 2, Log: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 2, Log: 2})
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1)}))
 		Expect(
 			logger.Calls.Log[1].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", f2.Name(), strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1)}))
-		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
-		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" = unchanged"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" = unchanged"}))
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 0, 0, 2}))
 	})
 
 	It(
@@ -246,7 +252,8 @@ var _ = Describe("adopt()", func() {
 
 		Expect(result).To(BeTrue())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 2})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 2, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1)}))
@@ -267,6 +274,7 @@ var _ = Describe("adopt()", func() {
 		defer f2.Close()
 
 		core.RecreateFile(f2.Name(), strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1))
+		f2.WriteString("foo")
 		os.Chmod(strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1), 0o400)
 
 		result, err := cmd.Adopt(commands.AdoptArgs{
@@ -291,7 +299,8 @@ var _ = Describe("adopt()", func() {
 		Expect(
 			unwrapErrors.Unwrap()[1],
 		).To(MatchError(fmt.Sprintf("open %s: permission denied", strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1))))
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 2})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 1, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Adopting %s to %s ...", f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1)}))
@@ -301,4 +310,392 @@ var _ = Describe("adopt()", func() {
 		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
 		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✕"}))
 	})
+
+	It("should adopt symlinks as symlinks", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		from := filepath.Join(homedir, ".config")
+
+		os.MkdirAll(filepath.Join(homedir, "src", "nvim"), os.ModePerm)
+		os.MkdirAll(from, os.ModePerm)
+		os.Symlink(filepath.Join(homedir, "src", "nvim"), filepath.Join(from, "nvim"))
+		os.Symlink("/etc/foo", filepath.Join(from, "foo"))
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From: from,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(
+			os.Readlink(filepath.Join(dotfilesFilesDir, ".config", "foo")),
+		).To(Equal("/etc/foo"))
+		Expect(
+			os.Readlink(filepath.Join(dotfilesFilesDir, ".config", "nvim")),
+		).To(Equal(filepath.Join(dotfilesFilesDir, "src", "nvim")))
+	})
+
+	It("should not adopt symlinks that point to their own dotfiles file", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".bashrc"), []byte("foo"), 0o600)
+		os.Symlink(filepath.Join(dotfilesFilesDir, ".bashrc"), filepath.Join(homedir, ".bashrc"))
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: filepath.Join(homedir, ".bashrc"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan.Entries).To(Equal([]commands.PlanEntry{{
+			Action: commands.PlanActionUnchanged,
+			From:   filepath.Join(homedir, ".bashrc"),
+			To:     filepath.Join(dotfilesFilesDir, ".bashrc"),
+		}}))
+	})
+
+	It("should not adopt anything in dry run mode", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		from, _ := os.MkdirTemp(homedir, "*")
+		f1, _ := os.CreateTemp(from, "1-*")
+		defer f1.Close()
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From:   from,
+			DryRun: true,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1))).To(BeFalse())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Dry run, no files will be changed"}))
+		Expect(
+			logger.Calls.Lognl[0].Args,
+		).To(Equal([]any{"%s %s to %s", "create   ", f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1)}))
+	})
+
+	It("should refuse to adopt files rendered from templates and show the diff", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"), []byte("{{ .OS }}\n"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".gitconfig"), []byte("foo\n"), 0o600)
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From: filepath.Join(homedir, ".gitconfig"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				TemplateData:     core.TemplateData{OS: "linux"},
+			},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(fmt.Sprintf(
+			"path %s is rendered from template %s, update the template instead",
+			filepath.Join(homedir, ".gitconfig"),
+			filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"),
+		)))
+		Expect(
+			fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl")),
+		).To(Equal([]byte("{{ .OS }}\n")))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 6})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[4].Args).To(Equal([]any{"-linux"}))
+		Expect(logger.Calls.Lognl[5].Args).To(Equal([]any{"+foo"}))
+	})
+	It("should encrypt adopted files into encrypted files", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("foo"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), encrypted, 0o600)
+		os.WriteFile(filepath.Join(homedir, ".netrc"), []byte("bar"), 0o600)
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Identity:         identityFile,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".netrc"))).To(BeFalse())
+		Expect(
+			string(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".netrc.age"))),
+		).To(HavePrefix("-----BEGIN AGE ENCRYPTED FILE-----"))
+
+		content, err := core.DecryptFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), identityFile)
+
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("bar"))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 0, 1, 0}))
+	})
+
+	It("should encrypt adopted files to all the configured recipients", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		otherIdentityFile := filepath.Join(workingDir, "other.txt")
+		identity, _ := age.GenerateX25519Identity()
+		other, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+		os.WriteFile(otherIdentityFile, []byte(other.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("foo"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), encrypted, 0o600)
+		os.WriteFile(filepath.Join(homedir, ".netrc"), []byte("bar"), 0o600)
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Identity:         identityFile,
+				Recipients:       []age.Recipient{identity.Recipient(), other.Recipient()},
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+
+		for _, file := range []string{identityFile, otherIdentityFile} {
+			content, err := core.DecryptFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), file)
+
+			Expect(err).To(BeNil())
+			Expect(string(content)).To(Equal("bar"))
+		}
+	})
+})
+
+var _ = Describe("PlanAdopt()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return an error if `from` is not a subdirectory of `homedir`", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		from, _ := os.MkdirTemp(workingDir, "*")
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: from,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(err).To(MatchError(fmt.Sprintf("path %s is not a subpath of %s", from, homedir)))
+	})
+
+	It("should plan a directory when `from` is the same as `dotfilesFilesDir`", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		f1, _ := os.CreateTemp(homedir, "1-*")
+		defer f1.Close()
+		f2, _ := os.CreateTemp(homedir, "2-*")
+		defer f2.Close()
+
+		core.RecreateFile(f1.Name(), strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1))
+		core.RecreateFile(f2.Name(), strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1))
+		f2.WriteString("foo")
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionUnchanged,
+					From:   f1.Name(),
+					To:     strings.Replace(f1.Name(), homedir, dotfilesFilesDir, 1),
+				},
+				{
+					Action: commands.PlanActionOverwrite,
+					From:   f2.Name(),
+					To:     strings.Replace(f2.Name(), homedir, dotfilesFilesDir, 1),
+				},
+			},
+		}))
+	})
+
+	It("should not plan ignored files", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(homedir, ".config", "gh"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".dotsignore"), []byte(".config/gh/hosts.yml\n"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "gh", "config.yml"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "gh", "hosts.yml"), []byte("foo"), 0o600)
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: filepath.Join(homedir, ".config"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(homedir, ".config", "gh", "config.yml"),
+					To:     filepath.Join(dotfilesFilesDir, ".config", "gh", "config.yml"),
+				},
+			},
+		}))
+	})
+
+	It("should plan files back into the layer that owns them", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		repoDir, _ := os.MkdirTemp(workingDir, "*")
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(repoDir, "home")},
+			{Name: "profiles/work", Dir: filepath.Join(repoDir, "profiles", "work")},
+		}
+
+		os.MkdirAll(layers[0].Dir, os.ModePerm)
+		os.MkdirAll(layers[1].Dir, os.ModePerm)
+		os.WriteFile(filepath.Join(layers[0].Dir, "1"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, "1"), []byte("work"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("changed"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "2"), []byte("new"), 0o600)
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: homedir,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: layers[0].Dir,
+				Layers:           layers,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionOverwrite,
+					From:   filepath.Join(homedir, "1"),
+					To:     filepath.Join(layers[1].Dir, "1"),
+				},
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(homedir, "2"),
+					To:     filepath.Join(layers[0].Dir, "2"),
+				},
+			},
+		}))
+	})
+
+	It("should plan every layer when `from` is the same as `dotfilesFilesDir`", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		repoDir, _ := os.MkdirTemp(workingDir, "*")
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(repoDir, "home")},
+			{Name: "profiles/work", Dir: filepath.Join(repoDir, "profiles", "work")},
+		}
+
+		os.MkdirAll(layers[0].Dir, os.ModePerm)
+		os.MkdirAll(layers[1].Dir, os.ModePerm)
+		os.WriteFile(filepath.Join(layers[0].Dir, "1"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[0].Dir, "2"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, "2"), []byte("work"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "2"), []byte("changed"), 0o600)
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: layers[0].Dir,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: layers[0].Dir,
+				Layers:           layers,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionUnchanged,
+					From:   filepath.Join(homedir, "1"),
+					To:     filepath.Join(layers[0].Dir, "1"),
+				},
+				{
+					Action: commands.PlanActionOverwrite,
+					From:   filepath.Join(homedir, "2"),
+					To:     filepath.Join(layers[1].Dir, "2"),
+				},
+			},
+		}))
+	})
+
+	It("should return an error if `from` is a subdirectory of a layer", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		repoDir, _ := os.MkdirTemp(homedir, "*")
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(repoDir, "home")},
+			{Name: "profiles/work", Dir: filepath.Join(repoDir, "profiles", "work")},
+		}
+
+		os.MkdirAll(filepath.Join(layers[1].Dir, "foo"), os.ModePerm)
+
+		plan, err := commands.PlanAdopt(commands.AdoptArgs{
+			From: filepath.Join(layers[1].Dir, "foo"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: layers[0].Dir,
+				Layers:           layers,
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(err).To(MatchError(fmt.Sprintf(
+			"path %s can not be a subpath of %s", filepath.Join(layers[1].Dir, "foo"), layers[1].Dir,
+		)))
+	})
 })
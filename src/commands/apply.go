@@ -4,9 +4,11 @@ import (
 	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/fatih/color"
 	"github.com/gookit/goutil/fsutil"
 	"github.com/m4rc3l05/dots/src/core"
@@ -15,103 +17,311 @@ import (
 type ApplyArgsExtra struct {
 	Homedir          string
 	DotfilesFilesDir string
+	StateDir         string
+	Ignore           []string
+	Layers           []core.Layer
+	TemplateData     core.TemplateData
+	Identity         string
+	Recipients       []age.Recipient
+	Hooks            core.ConfigHooks
+	ScriptsDir       string
+	MergeTool        string
 }
 
 type ApplyArgs struct {
-	From  string
-	Extra ApplyArgsExtra
+	From        string
+	DryRun      bool
+	Mode        core.DeployMode
+	Force       bool
+	Interactive bool
+	Prune       bool
+	Extra       ApplyArgsExtra
 }
 
-func (c Commands) Apply(args ApplyArgs) (bool, error) {
-	fromFormatted, err := filepath.Abs(args.From)
-	if err != nil {
-		return false, err
+func planApplyPath(from string, to string, args ApplyArgs) (PlanEntry, error) {
+	if core.IsTemplate(from) {
+		content, err := core.RenderTemplate(from, args.Extra.TemplateData)
+		if err != nil {
+			return PlanEntry{}, errors.Join(
+				fmt.Errorf("error rendering template %s", color.BlueString(from)),
+				err,
+			)
+		}
+
+		entry := makeContentPlanEntry(from, core.TemplateTarget(to), content)
+		entry.Template = true
+
+		return planApplyMerge(entry, args), nil
 	}
 
-	args.From = fromFormatted
+	if core.IsEncrypted(from) {
+		content, err := core.DecryptFile(from, args.Extra.Identity)
+		if err != nil {
+			return PlanEntry{}, errors.Join(
+				fmt.Errorf("error decrypting %s", color.BlueString(from)),
+				err,
+			)
+		}
+
+		entry := makeContentPlanEntry(from, core.EncryptedTarget(to), content)
+		entry.Encrypted = true
 
-	if !fsutil.PathExist(args.From) || !core.IsPathReadable(args.From) {
-		return false, fmt.Errorf(
+		return entry, nil
+	}
+
+	if args.Mode != core.DeployModeLink {
+		return planApplyMerge(planPath(from, to, args.Extra.DotfilesFilesDir, args.Extra.Homedir), args), nil
+	}
+
+	entry := makeSymlinkPlanEntry(from, to, from)
+
+	if entry.Action == PlanActionOverwrite && !args.Force && !core.IsSymlink(to) {
+		entry.Action = PlanActionBlocked
+	}
+
+	return entry, nil
+}
+
+func resolveApplyFrom(from string, layers []core.Layer) (string, core.Layer, error) {
+	fromFormatted, err := filepath.Abs(from)
+	if err != nil {
+		return "", core.Layer{}, err
+	}
+
+	if (!fsutil.PathExist(fromFormatted) || !core.IsPathReadable(fromFormatted)) &&
+		!core.IsSymlink(fromFormatted) {
+		return "", core.Layer{}, fmt.Errorf(
 			"path %s does not exists or is not readable",
-			color.BlueString(args.From),
+			color.BlueString(fromFormatted),
 		)
 	}
 
-	if !strings.HasPrefix(args.From, args.Extra.DotfilesFilesDir) {
-		return false, fmt.Errorf(
+	layer, ok := core.FindLayer(layers, fromFormatted)
+	if !ok {
+		return "", core.Layer{}, fmt.Errorf(
 			"path %s is not a subpath of %s",
-			color.BlueString(args.From), color.BlueString(args.Extra.DotfilesFilesDir),
+			color.BlueString(fromFormatted), color.BlueString(layers[0].Dir),
 		)
 	}
 
-	if fsutil.IsFile(args.From) {
-		to, err := filepath.Abs(
-			strings.Replace(args.From, args.Extra.DotfilesFilesDir, args.Extra.Homedir, 1),
-		)
+	return fromFormatted, layer, nil
+}
+
+func planApplyLayer(
+	root string,
+	layer core.Layer,
+	layers []core.Layer,
+	ignore core.IgnoreMatcher,
+	args ApplyArgs,
+	add func(entry PlanEntry),
+) error {
+	args.Extra.DotfilesFilesDir = layer.Dir
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
 		if err != nil {
-			return false, err
+			return err
 		}
 
-		c.Logger.Log(
-			"Applying %s to %s ...",
-			color.BlueString(args.From),
-			color.BlueString(to),
-		)
+		if isOtherLayerDir(layers, layer, path) {
+			return filepath.SkipDir
+		}
+
+		if isPathIgnored(ignore, layer.Dir, path, d.IsDir()) {
+			return skipIgnored(d)
+		}
+
+		if !isPlannable(d) {
+			return nil
+		}
+
+		to := strings.Replace(path, layer.Dir, args.Extra.Homedir, 1)
+
+		entry, err := planApplyPath(path, to, args)
+		if err != nil {
+			return err
+		}
+
+		add(entry)
+
+		return nil
+	})
+}
+
+func PlanApply(args ApplyArgs) (Plan, error) {
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	from, fromLayer, err := resolveApplyFrom(args.From, layers)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	ignores, err := loadLayersIgnoreMatchers(layers, args.Extra.Ignore)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if isPathIgnored(ignores[fromLayer.Dir], fromLayer.Dir, from, fsutil.IsDir(from)) {
+		return Plan{}, fmt.Errorf("path %s is ignored", color.BlueString(from))
+	}
+
+	relative := relativeTo(fromLayer.Dir, from)
+	plan := Plan{IsDir: !fsutil.IsFile(from) && !core.IsSymlink(from)}
+	indexes := map[string]int{}
 
-		if err := core.RecreateFile(args.From, to); err != nil {
-			c.Logger.Lognl(color.GreenString(" ✕"))
+	add := func(entry PlanEntry) {
+		if index, ok := indexes[entry.To]; ok {
+			plan.Entries[index] = entry
 
-			return false, errors.Join(fmt.Errorf(
-				"error applying %s to %s",
-				color.BlueString(args.From),
-				color.BlueString(to),
-			), err)
+			return
 		}
 
-		c.Logger.Lognl(color.GreenString(" ✓"))
+		indexes[entry.To] = len(plan.Entries)
+		plan.Entries = append(plan.Entries, entry)
+	}
+
+	for _, layer := range layers {
+		root := filepath.Join(layer.Dir, relative)
+
+		if _, err := os.Lstat(root); err != nil {
+			continue
+		}
+
+		if err := planApplyLayer(root, layer, layers, ignores[layer.Dir], args, add); err != nil {
+			return Plan{}, errors.Join(errors.New("error planning directory"), err)
+		}
+	}
+
+	if args.Prune {
+		entries, drifted, err := planPrune(layers, args.Extra.Homedir, args.Extra.StateDir, relative)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		plan.Entries = append(plan.Entries, entries...)
+		plan.Drifted = drifted
+	}
+
+	return plan, nil
+}
+
+func isWholeApply(args ApplyArgs) bool {
+	from, err := filepath.Abs(args.From)
+
+	return err == nil && from == args.Extra.DotfilesFilesDir
+}
+
+func (c Commands) Apply(args ApplyArgs) (bool, error) {
+	plan, err := PlanApply(args)
+	if err != nil {
+		return false, err
+	}
+
+	return c.applyPlan(plan, args)
+}
+
+func (c Commands) applyPlan(plan Plan, args ApplyArgs) (bool, error) {
+	var err error
+
+	ctx := hooksContext{
+		command:          applyMessages.command,
+		homedir:          args.Extra.Homedir,
+		dotfilesFilesDir: args.Extra.DotfilesFilesDir,
+		home:             func(entry PlanEntry) string { return entry.To },
+	}
+
+	c.warnLocalChanges(plan, args.Extra.StateDir, args.Extra.Homedir)
+	c.warnDriftedPrune(plan)
+
+	runScripts := isWholeApply(args) && args.Extra.ScriptsDir != "" && args.Extra.StateDir != ""
+
+	if args.DryRun {
+		c.logPlan(plan, applyMessages)
+		c.logHooks(core.HookStagePreApply, args.Extra.Hooks.PreApply, ctx.changedPaths(plan.Entries))
+
+		if runScripts {
+			c.logScripts(args.Extra.ScriptsDir, args.Extra.StateDir)
+		}
+
+		c.logHooks(core.HookStagePostApply, args.Extra.Hooks.PostApply, ctx.changedPaths(plan.Entries))
 
 		return true, nil
 	}
 
-	var errorsArr []error
+	var reversed Plan
 
-	err = filepath.WalkDir(args.From, func(path string, d fs.DirEntry, err error) error {
+	if args.Interactive {
+		plan, reversed, err = c.reviewPlan(plan, applyMessages, adoptMessages, reviewMerge{
+			ctx:       ctx,
+			stateDir:  args.Extra.StateDir,
+			mergeTool: args.Extra.MergeTool,
+		})
 		if err != nil {
-			return err
+			return false, err
 		}
+	}
 
-		if !d.Type().IsRegular() {
-			return nil
+	if err := c.runHooks(core.HookStagePreApply, args.Extra.Hooks.PreApply, plan.Entries, ctx); err != nil {
+		return false, err
+	}
+
+	plan = c.resolveConflicts(plan, args.Extra.MergeTool, args.Extra.Homedir)
+
+	if args.Extra.StateDir != "" {
+		if err := c.backupPlan(plan, args.Extra.Homedir, args.Extra.StateDir); err != nil {
+			return false, err
 		}
+	}
 
-		to := strings.Replace(path, args.Extra.DotfilesFilesDir, args.Extra.Homedir, 1)
+	changed, err := c.executePlan(plan, applyMessages)
 
-		c.Logger.Log("Applying %s to %s ...", color.BlueString(path), color.BlueString(to))
+	var reversedErr error
 
-		if err := core.RecreateFile(path, to); err != nil {
-			c.Logger.Lognl(color.RedString(" ✕"))
+	if len(reversed.Entries) > 0 {
+		_, reversedErr = c.executePlan(reversed, adoptMessages)
+	}
 
-			errorsArr = append(
-				errorsArr,
-				errors.Join(fmt.Errorf(
-					"error applying %s to %s",
-					color.BlueString(path),
-					color.BlueString(to),
-				), err),
-			)
-		} else {
-			c.Logger.Lognl(color.GreenString(" ✓"))
+	stateErr := recordState(append(unchangedEntries(plan), changed...), ctx, args.Extra.StateDir)
+
+	var scriptsErr error
+
+	if runScripts {
+		scriptsErr = c.runScripts(args.Extra.ScriptsDir, args.Extra.StateDir, ctx)
+	}
+
+	return joinPlanErrors(
+		applyMessages,
+		err,
+		reversedErr,
+		stateErr,
+		scriptsErr,
+		c.runHooks(core.HookStagePostApply, args.Extra.Hooks.PostApply, changed, ctx),
+	)
+}
+
+func (c Commands) backupPlan(plan Plan, homedir string, stateDir string) error {
+	var files []core.BackupFile
+
+	for _, entry := range plan.Entries {
+		switch entry.Action {
+		case PlanActionCreate:
+			files = append(files, core.BackupFile{Path: entry.To, ReplacedBy: entry.From, Created: true})
+		case PlanActionOverwrite, PlanActionMerge:
+			files = append(files, core.BackupFile{Path: entry.To, ReplacedBy: entry.From})
+		case PlanActionRemove:
+			files = append(files, core.BackupFile{Path: entry.To})
 		}
+	}
 
+	if len(files) <= 0 {
 		return nil
-	})
-	if err != nil {
-		errorsArr = append(errorsArr, err)
 	}
 
-	if len(errorsArr) > 0 {
-		errorsArr = append([]error{errors.New("error applying directory")}, errorsArr...)
+	manifest, err := core.CreateBackup(core.ResolveBackupsDir(stateDir), homedir, files)
+	if err != nil {
+		return errors.Join(errors.New("error backing up files"), err)
 	}
 
-	return len(errorsArr) <= 0, errors.Join(errorsArr...)
+	c.Logger.Infonl("Backed up %d file(s) to %s", len(files), color.BlueString(manifest.Id))
+
+	return nil
 }
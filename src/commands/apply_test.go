@@ -3,9 +3,13 @@ package commands_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
+	"filippo.io/age"
 	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
 	"github.com/m4rc3l05/dots/src/commands"
 	"github.com/m4rc3l05/dots/src/core"
 	"github.com/m4rc3l05/dots/src/testing"
@@ -115,7 +119,8 @@ var _ = Describe("adopt()", func() {
 
 		Expect(result).To(BeTrue())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 1, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Applying %s to %s ...", from.Name(), strings.Replace(from.Name(), dotfilesFilesDir, homedir, 1)}))
@@ -130,6 +135,7 @@ var _ = Describe("adopt()", func() {
 		to, _ := os.Create(strings.Replace(from.Name(), dotfilesFilesDir, homedir, 1))
 		defer to.Close()
 
+		from.WriteString("foo")
 		os.Chmod(to.Name(), 0o400)
 
 		result, err := cmd.Apply(commands.ApplyArgs{
@@ -148,7 +154,8 @@ var _ = Describe("adopt()", func() {
 		Expect(
 			unwrapErrors.Unwrap()[1],
 		).To(MatchError(fmt.Sprintf("open %s: permission denied", to.Name())))
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 0, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Applying %s to %s ...", from.Name(), strings.Replace(from.Name(), dotfilesFilesDir, homedir, 1)}))
@@ -173,7 +180,8 @@ var _ = Describe("adopt()", func() {
 
 		Expect(result).To(BeTrue())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 2})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 2, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Applying %s to %s ...", f1.Name(), strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1)}))
@@ -193,6 +201,7 @@ var _ = Describe("adopt()", func() {
 		defer f2.Close()
 
 		core.RecreateFile(f2.Name(), strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1))
+		f2.WriteString("foo")
 		os.Chmod(strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1), 0o400)
 
 		result, err := cmd.Apply(commands.ApplyArgs{
@@ -213,7 +222,8 @@ var _ = Describe("adopt()", func() {
 		Expect(
 			unwrapErrors.Unwrap()[1],
 		).To(MatchError(fmt.Sprintf("open %s: permission denied", strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1))))
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 2})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 1, 0, 0}))
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Applying %s to %s ...", f1.Name(), strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1)}))
@@ -223,4 +233,663 @@ var _ = Describe("adopt()", func() {
 		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
 		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✕"}))
 	})
+
+	It("should skip files that are unchanged", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		f1, _ := os.CreateTemp(dotfilesFilesDir, "1-*")
+		defer f1.Close()
+		to := strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1)
+
+		f1.WriteString("foo")
+		os.WriteFile(to, []byte("foo"), 0o600)
+		modTime := time.Now().Add(-time.Hour)
+		os.Chtimes(to, modTime, modTime)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		stat, _ := os.Stat(to)
+		Expect(stat.ModTime().Equal(modTime)).To(BeTrue())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" = unchanged"}))
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 0, 0, 1}))
+	})
+
+	It("should backup files before overwriting them", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		stateDir, _ := os.MkdirTemp(workingDir, "*")
+		f1, _ := os.CreateTemp(dotfilesFilesDir, "1-*")
+		defer f1.Close()
+		f2, _ := os.CreateTemp(dotfilesFilesDir, "2-*")
+		defer f2.Close()
+
+		f2.WriteString("foo")
+		os.WriteFile(strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1), []byte("bar"), 0o644)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 2, Log: 2, Lognl: 2})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Infonl[1].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 1, 1, 0}))
+		Expect(logger.Calls.Infonl[0].Args[0]).To(Equal("Backed up %d file(s) to %s"))
+		Expect(logger.Calls.Infonl[0].Args[1]).To(Equal(2))
+
+		backupDir := filepath.Join(core.ResolveBackupsDir(stateDir), logger.Calls.Infonl[0].Args[2].(string))
+
+		Expect(
+			string(fsutil.ReadAll(filepath.Join(core.ResolveBackupFilesDir(backupDir), filepath.Base(f2.Name())))),
+		).To(Equal("bar"))
+		Expect(fsutil.FileExist(filepath.Join(backupDir, "manifest.json"))).To(BeTrue())
+		Expect(string(fsutil.ReadAll(strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1)))).To(Equal("foo"))
+	})
+
+	It("should apply symlinks as symlinks", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".bash_profile"), []byte("foo"), 0o600)
+		os.Symlink(".bash_profile", filepath.Join(dotfilesFilesDir, ".profile"))
+		os.Symlink(filepath.Join(dotfilesFilesDir, ".bash_profile"), filepath.Join(dotfilesFilesDir, ".bashrc"))
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(core.IsSymlink(filepath.Join(homedir, ".profile"))).To(BeTrue())
+		Expect(os.Readlink(filepath.Join(homedir, ".profile"))).To(Equal(".bash_profile"))
+		Expect(os.Readlink(filepath.Join(homedir, ".bashrc"))).To(Equal(filepath.Join(homedir, ".bash_profile")))
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"%d created, %d updated, %d unchanged", 3, 0, 0}))
+	})
+
+	It("should replace symlinks with wrong targets", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.Symlink("foo", filepath.Join(dotfilesFilesDir, ".profile"))
+		os.Symlink("bar", filepath.Join(homedir, ".profile"))
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, ".profile"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan.Entries).To(Equal([]commands.PlanEntry{{
+			Action:     commands.PlanActionOverwrite,
+			From:       filepath.Join(dotfilesFilesDir, ".profile"),
+			To:         filepath.Join(homedir, ".profile"),
+			LinkTarget: "foo",
+		}}))
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, ".profile"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(os.Readlink(filepath.Join(homedir, ".profile"))).To(Equal("foo"))
+	})
+
+	It("should apply files as symlinks in link mode", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo"), []byte("foo"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Mode: core.DeployModeLink,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(
+			os.Readlink(filepath.Join(homedir, ".config", "foo")),
+		).To(Equal(filepath.Join(dotfilesFilesDir, ".config", "foo")))
+	})
+
+	It("should refuse to replace regular files with symlinks in link mode", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".bashrc"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("bar"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Mode: core.DeployModeLink,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeFalse())
+		unwrapErrors, _ := err.(interface{ Unwrap() []error })
+		Expect(unwrapErrors.Unwrap()[0]).To(MatchError("error applying directory"))
+		Expect(unwrapErrors.Unwrap()[1]).To(MatchError(fmt.Sprintf(
+			"path %s is not a symlink, use --force to replace it",
+			filepath.Join(homedir, ".bashrc"),
+		)))
+		Expect(core.IsSymlink(filepath.Join(homedir, ".bashrc"))).To(BeFalse())
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+	})
+
+	It("should replace regular files with symlinks in link mode if forced", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".bashrc"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("bar"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:  dotfilesFilesDir,
+			Mode:  core.DeployModeLink,
+			Force: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(
+			os.Readlink(filepath.Join(homedir, ".bashrc")),
+		).To(Equal(filepath.Join(dotfilesFilesDir, ".bashrc")))
+	})
+
+	It("should not apply anything in dry run mode", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		f1, _ := os.CreateTemp(dotfilesFilesDir, "1-*")
+		defer f1.Close()
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:   dotfilesFilesDir,
+			DryRun: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1))).To(BeFalse())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Dry run, no files will be changed"}))
+		Expect(
+			logger.Calls.Lognl[0].Args,
+		).To(Equal([]any{"%s %s to %s", "create   ", f1.Name(), strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1)}))
+	})
+
+	It("should report a record per file", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "3"), []byte("foo"), 0o600)
+		os.MkdirAll(filepath.Join(homedir, "3", "4"), os.ModePerm)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).ToNot(BeNil())
+		Expect(reporter.Records).To(HaveLen(3))
+		Expect(reporter.Records[:2]).To(Equal([]core.Record{
+			{
+				Command:     "apply",
+				Action:      "create",
+				Source:      filepath.Join(dotfilesFilesDir, "1"),
+				Destination: filepath.Join(homedir, "1"),
+				Status:      "ok",
+			},
+			{
+				Command:     "apply",
+				Action:      "unchanged",
+				Source:      filepath.Join(dotfilesFilesDir, "2"),
+				Destination: filepath.Join(homedir, "2"),
+				Status:      "ok",
+			},
+		}))
+		Expect(reporter.Records[2].Action).To(Equal("overwrite"))
+		Expect(reporter.Records[2].Status).To(Equal("error"))
+		Expect(reporter.Records[2].Error).To(HavePrefix(fmt.Sprintf(
+			"error applying %s to %s\n",
+			filepath.Join(dotfilesFilesDir, "3"),
+			filepath.Join(homedir, "3"),
+		)))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+	})
+
+	It("should report planned records on dry run", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("foo"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:   dotfilesFilesDir,
+			DryRun: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(reporter.Records).To(Equal([]core.Record{
+			{
+				Command:     "apply",
+				Action:      "create",
+				Source:      filepath.Join(dotfilesFilesDir, "1"),
+				Destination: filepath.Join(homedir, "1"),
+				Status:      "planned",
+			},
+		}))
+	})
+
+	It("should write decrypted files readable only by the user and keep their mode on later applies", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("token"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), encrypted, 0o644)
+		os.Chmod(filepath.Join(dotfilesFilesDir, ".netrc.age"), 0o644)
+
+		apply := func() commands.Plan {
+			args := commands.ApplyArgs{
+				From: dotfilesFilesDir,
+				Extra: commands.ApplyArgsExtra{
+					Homedir:          homedir,
+					DotfilesFilesDir: dotfilesFilesDir,
+					Identity:         identityFile,
+				},
+			}
+
+			plan, _ := commands.PlanApply(args)
+
+			cmd.Apply(args)
+
+			return plan
+		}
+
+		apply()
+
+		stat, _ := os.Stat(filepath.Join(homedir, ".netrc"))
+
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+
+		os.Chmod(filepath.Join(homedir, ".netrc"), 0o400)
+
+		Expect(apply().Entries[0].Action).To(Equal(commands.PlanActionUnchanged))
+
+		stat, _ = os.Stat(filepath.Join(homedir, ".netrc"))
+
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o400)))
+	})
+})
+
+var _ = Describe("PlanApply()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return an error if `from` is not a subdirectory of `dotfilesFilesDir`", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		from, _ := os.MkdirTemp(workingDir, "*")
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: from,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(
+			err,
+		).To(MatchError(fmt.Sprintf("path %s is not a subpath of %s", from, dotfilesFilesDir)))
+	})
+
+	It("should plan a single file", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		from, _ := os.CreateTemp(dotfilesFilesDir, "*")
+		defer from.Close()
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: from.Name(),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			Entries: []commands.PlanEntry{{
+				Action: commands.PlanActionCreate,
+				From:   from.Name(),
+				To:     strings.Replace(from.Name(), dotfilesFilesDir, homedir, 1),
+			}},
+		}))
+	})
+
+	It("should plan a directory", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		f1, _ := os.CreateTemp(dotfilesFilesDir, "1-*")
+		defer f1.Close()
+		f2, _ := os.CreateTemp(dotfilesFilesDir, "2-*")
+		defer f2.Close()
+		f3, _ := os.CreateTemp(dotfilesFilesDir, "3-*")
+		defer f3.Close()
+
+		f2.WriteString("foo")
+		f3.WriteString("bar")
+		core.RecreateFile(f2.Name(), strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1))
+		os.WriteFile(strings.Replace(f3.Name(), dotfilesFilesDir, homedir, 1), []byte("baz"), 0o644)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionCreate,
+					From:   f1.Name(),
+					To:     strings.Replace(f1.Name(), dotfilesFilesDir, homedir, 1),
+				},
+				{
+					Action: commands.PlanActionUnchanged,
+					From:   f2.Name(),
+					To:     strings.Replace(f2.Name(), dotfilesFilesDir, homedir, 1),
+				},
+				{
+					Action: commands.PlanActionOverwrite,
+					From:   f3.Name(),
+					To:     strings.Replace(f3.Name(), dotfilesFilesDir, homedir, 1),
+				},
+			},
+		}))
+	})
+
+	It("should not plan ignored files", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, "cache"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".dotsignore"), []byte("*.swp\ncache/\n"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1.swp"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "cache", "2"), []byte("foo"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(dotfilesFilesDir, "1"),
+					To:     filepath.Join(homedir, "1"),
+				},
+			},
+		}))
+	})
+
+	It("should return an error if `from` is ignored", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".dotsignore"), []byte("*.swp\n"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1.swp"), []byte("foo"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, "1.swp"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(
+			err,
+		).To(MatchError(fmt.Sprintf("path %s is ignored", filepath.Join(dotfilesFilesDir, "1.swp"))))
+	})
+
+	It("should plan files from overlay layers over the base layer", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		repoDir, _ := os.MkdirTemp(workingDir, "*")
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(repoDir, "home")},
+			{Name: "hosts/foo", Dir: filepath.Join(repoDir, "hosts", "foo")},
+		}
+
+		os.MkdirAll(filepath.Join(layers[0].Dir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(layers[1].Dir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(layers[0].Dir, ".config", "1"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[0].Dir, ".config", "2"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, ".config", "2"), []byte("host"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, ".config", "3"), []byte("host"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: filepath.Join(layers[0].Dir, ".config"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: layers[0].Dir,
+				Layers:           layers,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(layers[0].Dir, ".config", "1"),
+					To:     filepath.Join(homedir, ".config", "1"),
+				},
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(layers[1].Dir, ".config", "2"),
+					To:     filepath.Join(homedir, ".config", "2"),
+				},
+				{
+					Action: commands.PlanActionCreate,
+					From:   filepath.Join(layers[1].Dir, ".config", "3"),
+					To:     filepath.Join(homedir, ".config", "3"),
+				},
+			},
+		}))
+	})
+
+	It("should plan rendered templates", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"), []byte("{{ .Vars.email }}"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".gitconfig"), []byte("foo@bar.baz"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				TemplateData:     core.TemplateData{Vars: map[string]any{"email": "foo@bar.baz"}},
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action:   commands.PlanActionUnchanged,
+					From:     filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"),
+					To:       filepath.Join(homedir, ".gitconfig"),
+					Template: true,
+					Content:  []byte("foo@bar.baz"),
+				},
+			},
+		}))
+	})
+
+	It("should plan decrypted encrypted files", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("token"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), encrypted, 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Identity:         identityFile,
+			},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(plan).To(Equal(commands.Plan{
+			IsDir: true,
+			Entries: []commands.PlanEntry{
+				{
+					Action:    commands.PlanActionCreate,
+					From:      filepath.Join(dotfilesFilesDir, ".netrc.age"),
+					To:        filepath.Join(homedir, ".netrc"),
+					Encrypted: true,
+					Content:   []byte("token"),
+				},
+			},
+		}))
+	})
+
+	It("should return an error if an encrypted file can not be decrypted", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".netrc.age"), []byte("foo"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, ".netrc.age"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Identity:         filepath.Join(workingDir, "key.txt"),
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf(
+			"error decrypting %s", filepath.Join(dotfilesFilesDir, ".netrc.age"),
+		)))
+	})
+
+	It("should return an error if a template can not be rendered", func() {
+		homedir, _ := os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"), []byte("{{ .Foo }}"), 0o600)
+
+		plan, err := commands.PlanApply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"),
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(plan).To(Equal(commands.Plan{}))
+		Expect(err.Error()).To(ContainSubstring(fmt.Sprintf(
+			"error rendering template %s", filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"),
+		)))
+	})
 })
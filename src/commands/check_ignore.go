@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type CheckIgnoreArgsExtra struct {
+	Homedir          string
+	DotfilesFilesDir string
+	Ignore           []string
+	Layers           []core.Layer
+}
+
+type CheckIgnoreArgs struct {
+	Path  string
+	Extra CheckIgnoreArgsExtra
+}
+
+func resolveCheckIgnoreLayer(
+	path string,
+	layers []core.Layer,
+	extra CheckIgnoreArgsExtra,
+) (core.Layer, string, error) {
+	if layer, ok := core.FindLayer(layers, path); ok {
+		return layer, relativeTo(layer.Dir, path), nil
+	}
+
+	if core.IsSubpath(path, extra.Homedir) {
+		relative := relativeTo(extra.Homedir, path)
+
+		return resolveDestinationLayer(layers, relative), relative, nil
+	}
+
+	return core.Layer{}, "", fmt.Errorf(
+		"path %s is not a subpath of %s or %s",
+		color.BlueString(path),
+		color.BlueString(extra.DotfilesFilesDir),
+		color.BlueString(extra.Homedir),
+	)
+}
+
+func (c Commands) CheckIgnore(args CheckIgnoreArgs) (bool, error) {
+	if args.Path == "" {
+		return false, errors.New("a path must be provided")
+	}
+
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return false, err
+	}
+
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	layer, relative, err := resolveCheckIgnoreLayer(path, layers, args.Extra)
+	if err != nil {
+		return false, err
+	}
+
+	ignore, err := loadIgnoreMatcher(layer.Dir, args.Extra.Ignore)
+	if err != nil {
+		return false, err
+	}
+
+	rule, ok := ignore.Match(relative, fsutil.IsDir(path) || strings.HasSuffix(args.Path, "/"))
+	if !ok {
+		c.Logger.Infonl("Path %s is not ignored", color.BlueString(path))
+
+		return false, nil
+	}
+
+	c.Logger.Lognl(
+		"%s:%d:%s\t%s",
+		color.BlueString(rule.Source),
+		rule.Line,
+		color.YellowString(rule.Pattern),
+		color.BlueString(path),
+	)
+
+	if rule.Negate {
+		c.Logger.Infonl("Path %s is not ignored", color.BlueString(path))
+
+		return false, nil
+	}
+
+	return true, nil
+}
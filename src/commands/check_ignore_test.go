@@ -0,0 +1,112 @@
+package commands_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("checkIgnore()", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".dotsignore"), []byte("*.swp\n!keep.swp\n"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return an error if no path is provided", func() {
+		result, err := cmd.CheckIgnore(commands.CheckIgnoreArgs{
+			Extra: commands.CheckIgnoreArgsExtra{Homedir: homedir, DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("a path must be provided"))
+		testing.AssertSpyLoggerCalls(*logger, nil)
+	})
+
+	It("should return an error if the path is not under `dotfilesFilesDir` or `homedir`", func() {
+		result, err := cmd.CheckIgnore(commands.CheckIgnoreArgs{
+			Path:  workingDir,
+			Extra: commands.CheckIgnoreArgsExtra{Homedir: homedir, DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(fmt.Sprintf(
+			"path %s is not a subpath of %s or %s", workingDir, dotfilesFilesDir, homedir,
+		)))
+		testing.AssertSpyLoggerCalls(*logger, nil)
+	})
+
+	It("should print the rule that ignores a path under `homedir`", func() {
+		result, err := cmd.CheckIgnore(commands.CheckIgnoreArgs{
+			Path:  filepath.Join(homedir, "foo.swp"),
+			Extra: commands.CheckIgnoreArgsExtra{Homedir: homedir, DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{
+			"%s:%d:%s\t%s",
+			filepath.Join(dotfilesFilesDir, ".dotsignore"),
+			1,
+			"*.swp",
+			filepath.Join(homedir, "foo.swp"),
+		}))
+	})
+
+	It("should print the negated rule that matches a path under `dotfilesFilesDir`", func() {
+		result, err := cmd.CheckIgnore(commands.CheckIgnoreArgs{
+			Path:  filepath.Join(dotfilesFilesDir, "keep.swp"),
+			Extra: commands.CheckIgnoreArgsExtra{Homedir: homedir, DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1, Infonl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{
+			"%s:%d:%s\t%s",
+			filepath.Join(dotfilesFilesDir, ".dotsignore"),
+			2,
+			"!keep.swp",
+			filepath.Join(dotfilesFilesDir, "keep.swp"),
+		}))
+		Expect(
+			logger.Calls.Infonl[0].Args,
+		).To(Equal([]any{"Path %s is not ignored", filepath.Join(dotfilesFilesDir, "keep.swp")}))
+	})
+
+	It("should inform when a path is not ignored", func() {
+		result, err := cmd.CheckIgnore(commands.CheckIgnoreArgs{
+			Path:  filepath.Join(homedir, ".bashrc"),
+			Extra: commands.CheckIgnoreArgsExtra{Homedir: homedir, DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(
+			logger.Calls.Infonl[0].Args,
+		).To(Equal([]any{"Path %s is not ignored", filepath.Join(homedir, ".bashrc")}))
+	})
+})
@@ -1,9 +1,12 @@
 package commands
 
 import (
+	"errors"
 	"fmt"
 	"io/fs"
+	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/aymanbagabas/go-udiff"
@@ -13,101 +16,673 @@ import (
 )
 
 type DiffArgs struct {
-	FromDir string
-	ToDir   string
+	FromDir      string
+	ToDir        string
+	Path         string
+	Mode         core.DeployMode
+	Ignore       []string
+	Layers       []core.Layer
+	TemplateData core.TemplateData
+	Identity     string
+	StateDir     string
 }
 
-func (c Commands) Diff(args DiffArgs) (bool, error) {
-	hasFilesWithChanges := false
+type diffStatus int
+
+type diffSkip int
+
+type diffLink struct {
+	expected   string
+	actual     string
+	notSymlink bool
+}
+
+type diffModes struct {
+	from fs.FileMode
+	to   fs.FileMode
+}
+
+type diffResult struct {
+	from   string
+	to     string
+	status diffStatus
+	skip   diffSkip
+	diffs  string
+	reason string
+	drift  core.FileDrift
+	link   *diffLink
+	modes  *diffModes
+}
+
+const (
+	diffStatusInSync diffStatus = iota
+	diffStatusModified
+	diffStatusOnlyInDotfiles
+	diffStatusOnlyInHome
+	diffStatusSkipped
+	diffStatusConflict
+)
+
+const (
+	diffSkipNone diffSkip = iota
+	diffSkipSymlink
+	diffSkipSource
+	diffSkipDestination
+	diffSkipTemplate
+	diffSkipEncrypted
+)
+
+var diffStatusNames = map[diffStatus]string{
+	diffStatusInSync:         "in-sync",
+	diffStatusModified:       "modified",
+	diffStatusOnlyInDotfiles: "only-in-dotfiles",
+	diffStatusOnlyInHome:     "only-in-home",
+	diffStatusSkipped:        "skipped",
+	diffStatusConflict:       "conflict",
+}
+
+func diffRecord(result diffResult) core.Record {
+	return core.Record{
+		Command:     "diff",
+		Source:      result.from,
+		Destination: result.to,
+		Status:      diffStatusNames[result.status],
+		Drift:       string(result.drift),
+		Error:       result.reason,
+		Hunks:       core.SplitHunks(result.diffs),
+	}
+}
+
+func resolveDiffModes(from string, to string) *diffModes {
+	fromStat, err := os.Stat(from)
+	if err != nil {
+		return nil
+	}
+
+	toStat, err := os.Stat(to)
+	if err != nil {
+		return nil
+	}
+
+	if fromStat.Mode().Perm() == toStat.Mode().Perm() {
+		return nil
+	}
+
+	return &diffModes{from: fromStat.Mode().Perm(), to: toStat.Mode().Perm()}
+}
+
+func (c Commands) logModes(modes diffModes) {
+	c.Logger.Lognl(color.RedString("old mode %04o", modes.from))
+	c.Logger.Lognl(color.GreenString("new mode %04o", modes.to))
+}
+
+func (c Commands) logModeDiff(from string, to string) {
+	if modes := resolveDiffModes(from, to); modes != nil {
+		c.logModes(*modes)
+	}
+}
+
+func (c Commands) logUnifiedDiff(diffs string) {
+	if len(diffs) <= 0 {
+		return
+	}
+
+	for line := range strings.SplitSeq(strings.TrimSpace(diffs), "\n") {
+		if strings.HasPrefix(line, "@@") && strings.HasSuffix(line, "@@") {
+			c.Logger.Lognl(color.CyanString(line))
+		} else if len(line) > 1 && line[0] == '+' && line[1] != '+' {
+			c.Logger.Lognl(color.GreenString(line))
+		} else if len(line) > 1 && line[0] == '-' && line[1] != '-' {
+			c.Logger.Lognl(color.RedString(line))
+		} else {
+			c.Logger.Lognl(line)
+		}
+	}
+}
+
+func (c Commands) logDiffing(from string, to string) {
+	c.Logger.Log(
+		"Diffing %s against %s ...",
+		color.BlueString(from),
+		color.BlueString(to),
+	)
+}
+
+func (c Commands) logDiffLink(link diffLink, status diffStatus) {
+	if status == diffStatusOnlyInDotfiles {
+		c.Logger.Lognl(color.RedString("-symlink to %s", link.expected))
+
+		return
+	}
+
+	c.Logger.Lognl(color.RedString("-symlink to %s", link.expected))
+
+	if link.notSymlink {
+		c.Logger.Lognl(color.GreenString("+not a symlink"))
+	} else {
+		c.Logger.Lognl(color.GreenString("+symlink to %s", link.actual))
+	}
+}
+
+func (c Commands) logDiffSkip(result diffResult) {
+	switch result.skip {
+	case diffSkipSymlink:
+		c.Logger.Warnnl("Symlink %s is not readable, skipping...", color.BlueString(result.from))
+	case diffSkipSource:
+		c.Logger.Warnnl(
+			"File %s does not exists or is not a file or is not readable, skipping...",
+			color.BlueString(result.from),
+		)
+	case diffSkipDestination:
+		c.Logger.Warnnl(
+			"File %s does not exists or is not a file or is not readable, skipping...",
+			color.BlueString(result.to),
+		)
+	case diffSkipTemplate:
+		c.Logger.Warnnl(
+			"Template %s could not be rendered (%s), skipping...",
+			color.BlueString(result.from),
+			result.reason,
+		)
+	case diffSkipEncrypted:
+		c.Logger.Warnnl(
+			"File %s could not be decrypted (%s), skipping...",
+			color.BlueString(result.from),
+			result.reason,
+		)
+	}
+}
+
+func (c Commands) logDiffResult(result diffResult) {
+	switch result.status {
+	case diffStatusSkipped:
+		c.logDiffSkip(result)
+
+		return
+	case diffStatusOnlyInHome:
+		c.Logger.Warnnl(
+			"File %s only exists in home, it would be picked up by adopt",
+			color.BlueString(result.to),
+		)
+
+		return
+	}
+
+	c.logDiffing(result.from, result.to)
+
+	if result.status == diffStatusInSync {
+		c.Logger.Lognl(color.GreenString(" ✓"))
+
+		return
+	}
+
+	c.Logger.Lognl(color.RedString(" ✕"))
+
+	if result.link != nil {
+		c.logDiffLink(*result.link, result.status)
+	}
+
+	if result.modes != nil {
+		c.logModes(*result.modes)
+	}
+
+	c.logUnifiedDiff(result.diffs)
+
+	if result.status == diffStatusConflict {
+		c.Logger.Warnnl(
+			"File %s changed both in ~/ and in the dotfiles files since it was last applied, apply will merge it",
+			color.BlueString(result.to),
+		)
+	}
+
+	if drift := formatDrift(result.drift); drift != "" {
+		c.Logger.Lognl(color.HiBlackString("(%s since last apply)", drift))
+	}
+}
+
+func diffSymlink(from string, to string, args DiffArgs) diffResult {
+	target, err := os.Readlink(from)
+	if err != nil {
+		return diffResult{status: diffStatusSkipped, skip: diffSkipSymlink, reason: err.Error()}
+	}
+
+	expected := core.MapLinkTarget(target, args.FromDir, args.ToDir)
+
+	if _, err := os.Lstat(to); err != nil {
+		return diffResult{status: diffStatusOnlyInDotfiles, link: &diffLink{expected: expected}}
+	}
+
+	actual, err := os.Readlink(to)
+
+	if err == nil && actual == expected {
+		return diffResult{status: diffStatusInSync}
+	}
+
+	return diffResult{
+		status: diffStatusModified,
+		link:   &diffLink{expected: expected, actual: actual, notSymlink: err != nil},
+	}
+}
+
+func diffLinkMode(from string, to string) diffResult {
+	if _, err := os.Lstat(to); err != nil {
+		return diffResult{status: diffStatusOnlyInDotfiles, link: &diffLink{expected: from}}
+	}
+
+	actual, err := os.Readlink(to)
+
+	if err == nil && actual == from {
+		return diffResult{status: diffStatusInSync}
+	}
+
+	result := diffResult{
+		status: diffStatusModified,
+		link:   &diffLink{expected: from, actual: actual, notSymlink: err != nil},
+	}
+
+	if err != nil && fsutil.IsFile(from) && core.IsPathReadable(to) {
+		result.diffs = udiff.Unified(from, to, string(fsutil.ReadFile(from)), string(fsutil.ReadFile(to)))
+	}
+
+	return result
+}
+
+func diffFile(from string, to string) diffResult {
+	if !fsutil.FileExist(from) || !core.IsPathReadable(from) {
+		return diffResult{
+			status: diffStatusSkipped,
+			skip:   diffSkipSource,
+			reason: "source does not exists or is not a file or is not readable",
+		}
+	}
+
+	if _, err := os.Lstat(to); err != nil {
+		return diffResult{
+			status: diffStatusOnlyInDotfiles,
+			diffs:  udiff.Unified(from, os.DevNull, string(fsutil.ReadFile(from)), ""),
+		}
+	}
+
+	if !fsutil.FileExist(to) || !core.IsPathReadable(to) {
+		return diffResult{
+			status: diffStatusSkipped,
+			skip:   diffSkipDestination,
+			reason: "destination does not exists or is not a file or is not readable",
+		}
+	}
+
+	return diffModified(from, to, fsutil.ReadFile(from), resolveDiffModes(from, to))
+}
+
+func diffTemplate(from string, to string, data core.TemplateData) diffResult {
+	content, err := core.RenderTemplate(from, data)
+	if err != nil {
+		return diffResult{status: diffStatusSkipped, skip: diffSkipTemplate, reason: err.Error()}
+	}
+
+	return diffContent(from, to, content)
+}
+
+func diffEncrypted(from string, to string, identity string) diffResult {
+	content, err := core.DecryptFile(from, identity)
+	if err != nil {
+		return diffResult{status: diffStatusSkipped, skip: diffSkipEncrypted, reason: err.Error()}
+	}
+
+	return diffContent(from, to, content)
+}
+
+func diffContent(from string, to string, content []byte) diffResult {
+	if _, err := os.Lstat(to); err != nil {
+		return diffResult{
+			status: diffStatusOnlyInDotfiles,
+			diffs:  udiff.Unified(from, os.DevNull, string(content), ""),
+		}
+	}
+
+	var modes *diffModes
+
+	if !core.IsEncrypted(from) {
+		modes = resolveDiffModes(from, to)
+	}
+
+	return diffModified(from, to, content, modes)
+}
+
+func diffModified(from string, to string, content []byte, modes *diffModes) diffResult {
+	diffs := udiff.Unified(from, to, string(content), string(fsutil.ReadFile(to)))
+
+	if len(diffs) <= 0 && modes == nil {
+		return diffResult{status: diffStatusInSync}
+	}
 
+	return diffResult{status: diffStatusModified, diffs: diffs, modes: modes}
+}
+
+func visitOnlyInHome(
+	args DiffArgs,
+	layers []core.Layer,
+	ignore core.IgnoreMatcher,
+	path string,
+	d fs.DirEntry,
+	visit func(path string),
+) {
+	if isPathIgnored(ignore, args.ToDir, path, d.IsDir()) || !isPlannable(d) {
+		return
+	}
+
+	if _, ok := core.FindOwnerLayer(layers, relativeTo(args.ToDir, path)); ok {
+		return
+	}
+
+	visit(path)
+}
+
+func walkOnlyInHome(
+	args DiffArgs,
+	layers []core.Layer,
+	ignore core.IgnoreMatcher,
+	dir string,
+	visit func(path string),
+) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return
+	}
+
+	for _, entry := range entries {
+		visitOnlyInHome(args, layers, ignore, filepath.Join(dir, entry.Name()), entry, visit)
+	}
+}
+
+func resolveTrackedDirs(
+	layers []core.Layer,
+	ignores map[string]core.IgnoreMatcher,
+	scope string,
+) []string {
+	var trackedDirs []string
+
+	for _, layer := range layers {
+		_ = filepath.WalkDir(layer.Dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil || !d.IsDir() || path == layer.Dir {
+				return nil
+			}
+
+			if isOtherLayerDir(layers, layer, path) || isPathIgnored(ignores[layer.Dir], layer.Dir, path, true) {
+				return filepath.SkipDir
+			}
+
+			relative := relativeTo(layer.Dir, path)
+
+			if isInScope(relative, scope) && !slices.Contains(trackedDirs, relative) {
+				trackedDirs = append(trackedDirs, relative)
+			}
+
+			return nil
+		})
+	}
+
+	slices.Sort(trackedDirs)
+
+	return trackedDirs
+}
+
+func walkUntracked(
+	args DiffArgs,
+	layers []core.Layer,
+	ignores map[string]core.IgnoreMatcher,
+	scope string,
+	visit func(path string),
+) {
+	ignore := ignores[layers[0].Dir]
+
+	if scope != "" {
+		path := filepath.Join(args.ToDir, scope)
+
+		if stat, err := os.Lstat(path); err == nil && !stat.IsDir() {
+			visitOnlyInHome(args, layers, ignore, path, fs.FileInfoToDirEntry(stat), visit)
+
+			return
+		}
+	}
+
+	for _, relative := range resolveTrackedDirs(layers, ignores, scope) {
+		trackedDir := filepath.Join(args.ToDir, relative)
+
+		if !fsutil.IsDir(trackedDir) {
+			continue
+		}
+
+		walkOnlyInHome(args, layers, ignore, trackedDir, visit)
+	}
+}
+
+func resolveDiffScope(args DiffArgs, layers []core.Layer) (string, error) {
+	if args.Path == "" {
+		return "", nil
+	}
+
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if _, ok := core.FindLayer(layers, path); ok {
+		path, layer, err := resolveApplyFrom(path, layers)
+		if err != nil {
+			return "", err
+		}
+
+		return relativeTo(layer.Dir, path), nil
+	}
+
+	path, err = resolveAdoptFrom(path, args.ToDir, layers)
+	if err != nil {
+		return "", err
+	}
+
+	return relativeTo(args.ToDir, path), nil
+}
+
+func resolveSourceRoot(root string) string {
+	if _, err := os.Lstat(root); err == nil {
+		return root
+	}
+
+	for _, suffix := range []string{core.TemplateSuffix, core.EncryptedSuffix} {
+		if _, err := os.Lstat(root + suffix); err == nil {
+			return root + suffix
+		}
+	}
+
+	return root
+}
+
+func walkLayer(
+	args DiffArgs,
+	layer core.Layer,
+	layers []core.Layer,
+	ignore core.IgnoreMatcher,
+	scope string,
+	visit func(args DiffArgs, from string, to string, d fs.DirEntry),
+) error {
+	args.FromDir = layer.Dir
+	root := resolveSourceRoot(filepath.Join(layer.Dir, scope))
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if errors.Is(err, fs.ErrNotExist) && path == root {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if isOtherLayerDir(layers, layer, path) {
+			return filepath.SkipDir
+		}
+
+		if isPathIgnored(ignore, layer.Dir, path, d.IsDir()) {
+			return skipIgnored(d)
+		}
+
+		if !isPlannable(d) {
+			return nil
+		}
+
+		if isShadowedBySource(path) {
+			return nil
+		}
+
+		relative := core.SourceTarget(relativeTo(layer.Dir, path))
+
+		if owner := resolveDestinationLayer(layers, relative); owner.Dir != layer.Dir {
+			return nil
+		}
+
+		visit(args, path, filepath.Join(args.ToDir, relative), d)
+
+		return nil
+	})
+}
+
+type diffWalk struct {
+	args    DiffArgs
+	layers  []core.Layer
+	scope   string
+	ignores map[string]core.IgnoreMatcher
+}
+
+func prepareDiffWalk(args DiffArgs) (diffWalk, error) {
 	fromFormatted, err := filepath.Abs(args.FromDir)
 	if err != nil {
-		return false, err
+		return diffWalk{}, err
 	}
 
 	args.FromDir = fromFormatted
 
 	toFormatted, err := filepath.Abs(args.ToDir)
 	if err != nil {
-		return false, err
+		return diffWalk{}, err
 	}
 
 	args.ToDir = toFormatted
 
 	if !fsutil.IsDir(args.FromDir) || !core.IsPathReadable(args.FromDir) {
-		return false, fmt.Errorf(
+		return diffWalk{}, fmt.Errorf(
 			"path %s does not exists or is not a directory or is not readable",
 			args.FromDir,
 		)
 	}
 
 	if !fsutil.IsDir(args.ToDir) || !core.IsPathReadable(args.ToDir) {
-		return false, fmt.Errorf(
+		return diffWalk{}, fmt.Errorf(
 			"path %s does not exists or is not a directory or is not readable",
 			args.ToDir,
 		)
 	}
 
-	err = filepath.WalkDir(args.FromDir, func(path string, d fs.DirEntry, err error) error {
-		if err != nil {
-			return err
-		}
+	layers := resolveLayers(args.Layers, args.FromDir)
 
-		if !d.Type().IsRegular() {
-			return nil
-		}
+	scope, err := resolveDiffScope(args, layers)
+	if err != nil {
+		return diffWalk{}, err
+	}
 
-		from := path
-		to := strings.Replace(from, args.FromDir, args.ToDir, 1)
+	ignores, err := loadLayersIgnoreMatchers(layers, args.Ignore)
+	if err != nil {
+		return diffWalk{}, err
+	}
 
-		if !fsutil.FileExist(from) || !core.IsPathReadable(from) {
-			c.Logger.Warnnl(
-				"File %s does not exists or is not a file or is not readable, skipping...",
-				color.BlueString(from),
-			)
+	return diffWalk{args: args, layers: layers, scope: scope, ignores: ignores}, nil
+}
 
-			return nil
+func (w diffWalk) walk(
+	visit func(args DiffArgs, from string, to string, d fs.DirEntry),
+	visitUntracked func(path string),
+) error {
+	for _, layer := range w.layers {
+		if err := walkLayer(w.args, layer, w.layers, w.ignores[layer.Dir], w.scope, visit); err != nil {
+			return err
 		}
+	}
 
-		if !fsutil.FileExist(to) || !core.IsPathReadable(to) {
-			c.Logger.Warnnl(
-				"File %s does not exists or is not a file or is not readable, skipping...",
-				color.BlueString(to),
-			)
+	if visitUntracked != nil {
+		walkUntracked(w.args, w.layers, w.ignores, w.scope, visitUntracked)
+	}
 
-			return nil
-		}
+	return nil
+}
 
-		c.Logger.Log(
-			"Diffing %s against %s ...",
-			color.BlueString(from),
-			color.BlueString(to),
-		)
+func (c Commands) Diff(args DiffArgs) (bool, error) {
+	summary := map[diffStatus]int{}
 
-		diffs := udiff.Unified(from, to, string(fsutil.ReadFile(from)), string(fsutil.ReadFile(to)))
+	walk, err := prepareDiffWalk(args)
+	if err != nil {
+		return false, err
+	}
 
-		if len(diffs) > 0 {
-			hasFilesWithChanges = true
+	var results []diffResult
 
-			c.Logger.Lognl(color.RedString(" ✕"))
+	err = walk.walk(
+		func(args DiffArgs, from string, to string, d fs.DirEntry) {
+			var result diffResult
 
-			for line := range strings.SplitSeq(strings.TrimSpace(diffs), "\n") {
-				if strings.HasPrefix(line, "@@") && strings.HasSuffix(line, "@@") {
-					c.Logger.Lognl(color.CyanString(line))
-				} else if len(line) > 1 && line[0] == '+' && line[1] != '+' {
-					c.Logger.Lognl(color.GreenString(line))
-				} else if len(line) > 1 && line[0] == '-' && line[1] != '-' {
-					c.Logger.Lognl(color.RedString(line))
-				} else {
-					c.Logger.Lognl(line)
-				}
+			switch {
+			case core.IsTemplate(from):
+				result = diffTemplate(from, to, args.TemplateData)
+			case core.IsEncrypted(from):
+				result = diffEncrypted(from, to, args.Identity)
+			case args.Mode == core.DeployModeLink:
+				result = diffLinkMode(from, to)
+			case d.Type()&fs.ModeSymlink != 0:
+				result = diffSymlink(from, to, args)
+			default:
+				result = diffFile(from, to)
 			}
-		} else {
-			c.Logger.Lognl(color.GreenString(" ✓"))
-		}
 
-		return nil
-	})
+			result.from = from
+			result.to = to
+
+			if result.status == diffStatusModified && isConflicted(args, from, to) {
+				result.status = diffStatusConflict
+			}
+
+			if result.status == diffStatusModified || result.status == diffStatusConflict {
+				result.drift = resolveDrift(args.StateDir, args.ToDir, from, to)
+			}
+
+			summary[result.status] += 1
+			results = append(results, result)
+		},
+		func(path string) {
+			summary[diffStatusOnlyInHome] += 1
+			results = append(results, diffResult{status: diffStatusOnlyInHome, to: path})
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	renderResults(c, results, diffRecord, c.logDiffResult)
+
+	hasChanges := summary[diffStatusModified] > 0 ||
+		summary[diffStatusOnlyInDotfiles] > 0 ||
+		summary[diffStatusOnlyInHome] > 0 ||
+		summary[diffStatusConflict] > 0
+
+	if hasChanges {
+		c.Logger.Infonl(
+			"%d modified, %d only in dotfiles, %d only in home",
+			summary[diffStatusModified],
+			summary[diffStatusOnlyInDotfiles],
+			summary[diffStatusOnlyInHome],
+		)
+	}
+
+	if summary[diffStatusConflict] > 0 {
+		c.Logger.Warnnl("%d conflicted", summary[diffStatusConflict])
+	}
 
-	return err == nil && !hasFilesWithChanges, err
+	return !hasChanges, nil
 }
@@ -3,11 +3,13 @@ package commands_test
 import (
 	"fmt"
 	"os"
+	"path/filepath"
 	"strings"
 
+	"filippo.io/age"
 	"github.com/fatih/color"
-	"github.com/gookit/goutil/fsutil"
 	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
 	"github.com/m4rc3l05/dots/src/testing"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -167,29 +169,64 @@ var _ = Describe("diff()", func() {
 		)
 	})
 
-	It("should not diff anything if no matching file exists on `toDir`", func() {
+	It("should show a full removal diff if no matching file exists on `toDir`", func() {
 		fromDir, _ := os.MkdirTemp(workingDir, "*")
 		toDir, _ := os.MkdirTemp(workingDir, "*")
 		from, _ := os.CreateTemp(fromDir, "*")
 		defer from.Close()
 		to := strings.Replace(from.Name(), fromDir, toDir, 1)
 
+		from.WriteString("foo\n")
+
 		result, err := cmd.Diff(commands.DiffArgs{
 			FromDir: fromDir,
 			ToDir:   toDir,
 		})
 
-		Expect(result).To(BeTrue())
+		Expect(result).To(BeFalse())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Warnnl: 1})
-		Expect(logger.Calls.Warnnl[0].Args).To(
-			Equal(
-				[]any{
-					"File %s does not exists or is not a file or is not readable, skipping...",
-					to,
-				},
-			),
-		)
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 5})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{"Diffing %s against %s ...", from.Name(), to}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"--- " + from.Name()}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"+++ " + os.DevNull}))
+		Expect(logger.Calls.Lognl[3].Args).To(Equal([]any{"@@ -1 +0,0 @@"}))
+		Expect(logger.Calls.Lognl[4].Args).To(Equal([]any{"-foo"}))
+		Expect(
+			logger.Calls.Infonl[0].Args,
+		).To(Equal([]any{"%d modified, %d only in dotfiles, %d only in home", 0, 1, 0}))
+	})
+
+	It("should report files only in home within tracked directories", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(fromDir, ".config", "foo"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, ".config", "foo", "bar"), os.ModePerm)
+		os.WriteFile(filepath.Join(fromDir, ".config", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "foo", "bar", "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "foo", "3"), []byte("foo"), 0o600)
+		os.MkdirAll(filepath.Join(toDir, ".config", "chromium", "Default"), os.ModePerm)
+		os.WriteFile(filepath.Join(toDir, ".config", "chromium", "Default", "Cookies"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".untracked"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 1, Warnnl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Warnnl[0].Args).To(Equal([]any{
+			"File %s only exists in home, it would be picked up by adopt",
+			filepath.Join(toDir, ".config", "foo", "3"),
+		}))
+		Expect(
+			logger.Calls.Infonl[0].Args,
+		).To(Equal([]any{"%d modified, %d only in dotfiles, %d only in home", 0, 0, 1}))
 	})
 
 	It("should not diff anything if matching file is not readable on `toDir`", func() {
@@ -199,7 +236,7 @@ var _ = Describe("diff()", func() {
 		defer from.Close()
 		to := strings.Replace(from.Name(), fromDir, toDir, 1)
 
-		fsutil.CopyFile(from.Name(), to)
+		core.RecreateFile(from.Name(), to)
 		os.Chmod(to, 0o300)
 
 		result, err := cmd.Diff(commands.DiffArgs{
@@ -228,7 +265,7 @@ var _ = Describe("diff()", func() {
 		to := strings.Replace(from.Name(), fromDir, toDir, 1)
 
 		from.WriteString("foobar")
-		fsutil.CopyFile(from.Name(), to)
+		core.RecreateFile(from.Name(), to)
 
 		result, err := cmd.Diff(commands.DiffArgs{
 			FromDir: fromDir,
@@ -255,9 +292,9 @@ var _ = Describe("diff()", func() {
 		to2 := strings.Replace(from2.Name(), fromDir, toDir, 1)
 
 		from.WriteString("foobar")
-		fsutil.CopyFile(from.Name(), to)
+		core.RecreateFile(from.Name(), to)
 		from2.WriteString("foobuz")
-		fsutil.CopyFile(from2.Name(), to2)
+		core.RecreateFile(from2.Name(), to2)
 		from2.Truncate(0)
 		from2.Seek(0, 0)
 		from2.WriteString("foobiz")
@@ -269,7 +306,7 @@ var _ = Describe("diff()", func() {
 
 		Expect(result).To(BeFalse())
 		Expect(err).To(BeNil())
-		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 9, Log: 2})
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 9, Log: 2})
 		Expect(
 			logger.Calls.Log[0].Args,
 		).To(Equal([]any{"Diffing %s against %s ...", from.Name(), to}))
@@ -288,4 +325,409 @@ var _ = Describe("diff()", func() {
 		Expect(logger.Calls.Lognl[7].Args).To(Equal([]any{"+foobuz"}))
 		Expect(logger.Calls.Lognl[8].Args).To(Equal([]any{"\\ No newline at end of file"}))
 	})
+
+	It("should diff and show mode differences if files have different modes", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+		from, _ := os.CreateTemp(fromDir, "*")
+		defer from.Close()
+		to := strings.Replace(from.Name(), fromDir, toDir, 1)
+
+		from.WriteString("foobar")
+		core.RecreateFile(from.Name(), to)
+		os.Chmod(from.Name(), 0o755)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 3, Log: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"old mode 0755"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"new mode 0600"}))
+	})
+
+	It("should diff symlinks by their targets", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.Symlink(filepath.Join(fromDir, "foo"), filepath.Join(fromDir, "1"))
+		os.Symlink(filepath.Join(toDir, "foo"), filepath.Join(toDir, "1"))
+		os.Symlink("bar", filepath.Join(fromDir, "2"))
+		os.Symlink("baz", filepath.Join(toDir, "2"))
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 4, Log: 2})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"-symlink to bar"}))
+		Expect(logger.Calls.Lognl[3].Args).To(Equal([]any{"+symlink to baz"}))
+	})
+
+	It("should show symlinks missing on `toDir` as removed", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.Symlink("bar", filepath.Join(fromDir, "1"))
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 2, Log: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"-symlink to bar"}))
+	})
+
+	It("should consider correct symlinks as in sync in link mode", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(fromDir, "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, "2"), []byte("foo"), 0o600)
+		os.Symlink(filepath.Join(fromDir, "1"), filepath.Join(toDir, "1"))
+		os.WriteFile(filepath.Join(toDir, "2"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+			Mode:    core.DeployModeLink,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 4, Log: 2})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"-symlink to " + filepath.Join(fromDir, "2")}))
+		Expect(logger.Calls.Lognl[3].Args).To(Equal([]any{"+not a symlink"}))
+	})
+
+	It("should only diff the provided path under `fromDir`", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(fromDir, "foo"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(fromDir, "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, "2"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+			Path:    filepath.Join(fromDir, "foo"),
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(fromDir, "foo", "1"),
+			filepath.Join(toDir, "foo", "1"),
+		}))
+	})
+
+	It("should only diff the provided path under `toDir`", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(fromDir, "foo"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(fromDir, "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "foo", "1"), []byte("bar"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "foo", "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, "3"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+			Path:    filepath.Join(toDir, "foo", "1"),
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 8})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(fromDir, "foo", "1"),
+			filepath.Join(toDir, "foo", "1"),
+		}))
+		Expect(
+			logger.Calls.Infonl[0].Args,
+		).To(Equal([]any{"%d modified, %d only in dotfiles, %d only in home", 1, 0, 0}))
+	})
+
+	It("should report a scoped path that only exists in home", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(toDir, "1"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+			Path:    filepath.Join(toDir, "1"),
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Warnnl: 1})
+		Expect(logger.Calls.Warnnl[0].Args).To(Equal([]any{
+			"File %s only exists in home, it would be picked up by adopt",
+			filepath.Join(toDir, "1"),
+		}))
+	})
+
+	It("should return an error if the provided path is not under `fromDir` or `toDir`", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+			Path:    workingDir,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(fmt.Sprintf("path %s is not a subpath of %s", workingDir, toDir)))
+		testing.AssertSpyLoggerCalls(*logger, nil)
+	})
+
+	It("should not diff ignored files", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.MkdirAll(filepath.Join(fromDir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(fromDir, ".dotsignore"), []byte("*.swp\n"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, ".config", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, ".config", "2.swp"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "3.swp"), []byte("foo"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: fromDir,
+			ToDir:   toDir,
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+	})
+
+	It("should diff files from the layer that owns them", func() {
+		repoDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(repoDir, "home")},
+			{Name: "hosts/foo", Dir: filepath.Join(repoDir, "hosts", "foo")},
+		}
+
+		os.MkdirAll(filepath.Join(layers[0].Dir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(layers[1].Dir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(layers[0].Dir, ".config", "1"), []byte("base"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, ".config", "1"), []byte("host"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, ".config", "2"), []byte("host"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "1"), []byte("host"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "2"), []byte("host"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir: layers[0].Dir,
+			ToDir:   toDir,
+			Layers:  layers,
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(layers[1].Dir, ".config", "1"),
+			filepath.Join(toDir, ".config", "1"),
+		}))
+		Expect(logger.Calls.Log[1].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(layers[1].Dir, ".config", "2"),
+			filepath.Join(toDir, ".config", "2"),
+		}))
+	})
+
+	It("should diff the rendered output of templates", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(fromDir, "1.tmpl"), []byte("{{ .OS }}\n"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, "2.tmpl"), []byte("{{ .Arch }}\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "1"), []byte("linux\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "2"), []byte("arm64\n"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir:      fromDir,
+			ToDir:        toDir,
+			TemplateData: core.TemplateData{OS: "linux", Arch: "amd64"},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 7})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(fromDir, "1.tmpl"),
+			filepath.Join(toDir, "1"),
+		}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[5].Args).To(Equal([]any{"-amd64"}))
+		Expect(logger.Calls.Lognl[6].Args).To(Equal([]any{"+arm64"}))
+	})
+
+	It("should diff the decrypted content of encrypted files", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("foo\n"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(fromDir, "1.age"), encrypted, 0o600)
+		os.WriteFile(filepath.Join(toDir, "1"), []byte("bar\n"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir:  fromDir,
+			ToDir:    toDir,
+			Identity: identityFile,
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 1, Lognl: 6})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Diffing %s against %s ...",
+			filepath.Join(fromDir, "1.age"),
+			filepath.Join(toDir, "1"),
+		}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[4].Args).To(Equal([]any{"-foo"}))
+		Expect(logger.Calls.Lognl[5].Args).To(Equal([]any{"+bar"}))
+	})
+
+	It("should diff a template or an encrypted file scoped by its ~/ path", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("foo\n"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(fromDir, ".gitconfig.tmpl"), []byte("{{ .OS }}\n"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, ".secret.age"), encrypted, 0o600)
+		os.WriteFile(filepath.Join(toDir, ".gitconfig"), []byte("darwin\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".secret"), []byte("bar\n"), 0o600)
+
+		for _, name := range []string{".gitconfig", ".secret"} {
+			logger = testing.MakeSpyLogger()
+			cmd = commands.Commands{Logger: logger}
+
+			result, err := cmd.Diff(commands.DiffArgs{
+				FromDir:      fromDir,
+				ToDir:        toDir,
+				Path:         filepath.Join(toDir, name),
+				TemplateData: core.TemplateData{OS: "linux"},
+				Identity:     identityFile,
+			})
+
+			Expect(result).To(BeFalse())
+			Expect(err).To(BeNil())
+			Expect(logger.Calls.Log).To(HaveLen(1))
+			Expect(logger.Calls.Log[0].Args[2]).To(Equal(filepath.Join(toDir, name)))
+		}
+	})
+
+	It("should skip encrypted files that can not be decrypted", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(fromDir, "1.age"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(toDir, "1"), []byte("bar"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{
+			FromDir:  fromDir,
+			ToDir:    toDir,
+			Identity: filepath.Join(workingDir, "key.txt"),
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Warnnl: 1})
+		Expect(logger.Calls.Warnnl[0].Args[0]).To(Equal("File %s could not be decrypted (%s), skipping..."))
+	})
+
+	It("should report a record per file", func() {
+		fromDir, _ := os.MkdirTemp(workingDir, "*")
+		toDir, _ := os.MkdirTemp(workingDir, "*")
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		os.MkdirAll(filepath.Join(fromDir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(toDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(fromDir, ".config", "1"), []byte("foo\n"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, ".config", "2"), []byte("foo\n"), 0o600)
+		os.WriteFile(filepath.Join(fromDir, ".config", "3"), []byte("foo\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "1"), []byte("foo\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "2"), []byte("bar\n"), 0o600)
+		os.WriteFile(filepath.Join(toDir, ".config", "4"), []byte("foo\n"), 0o600)
+
+		result, err := cmd.Diff(commands.DiffArgs{FromDir: fromDir, ToDir: toDir})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		Expect(reporter.Records).To(Equal([]core.Record{
+			{
+				Command:     "diff",
+				Source:      filepath.Join(fromDir, ".config", "1"),
+				Destination: filepath.Join(toDir, ".config", "1"),
+				Status:      "in-sync",
+			},
+			{
+				Command:     "diff",
+				Source:      filepath.Join(fromDir, ".config", "2"),
+				Destination: filepath.Join(toDir, ".config", "2"),
+				Status:      "modified",
+				Hunks:       []string{"@@ -1 +1 @@\n-foo\n+bar"},
+			},
+			{
+				Command:     "diff",
+				Source:      filepath.Join(fromDir, ".config", "3"),
+				Destination: filepath.Join(toDir, ".config", "3"),
+				Status:      "only-in-dotfiles",
+				Hunks:       []string{"@@ -1 +0,0 @@\n-foo"},
+			},
+			{
+				Command:     "diff",
+				Destination: filepath.Join(toDir, ".config", "4"),
+				Status:      "only-in-home",
+			},
+		}))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+	})
 })
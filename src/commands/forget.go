@@ -0,0 +1,105 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type ForgetArgsExtra struct {
+	Homedir          string
+	DotfilesFilesDir string
+	StateDir         string
+	Ignore           []string
+	Layers           []core.Layer
+}
+
+type ForgetArgs struct {
+	Path   string
+	DryRun bool
+	Extra  ForgetArgsExtra
+}
+
+func PlanForget(args ForgetArgs) (Plan, error) {
+	if args.Path == "" {
+		return Plan{}, errors.New("a path must be provided")
+	}
+
+	walk, err := prepareDiffWalk(DiffArgs{
+		FromDir: args.Extra.DotfilesFilesDir,
+		ToDir:   args.Extra.Homedir,
+		Path:    args.Path,
+		Ignore:  args.Extra.Ignore,
+		Layers:  args.Extra.Layers,
+	})
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if walk.scope == "" {
+		return Plan{}, fmt.Errorf(
+			"path %s would forget every managed file, pass a subpath instead",
+			color.BlueString(args.Path),
+		)
+	}
+
+	plan := Plan{IsDir: true}
+
+	err = walk.walk(func(_ DiffArgs, from string, to string, _ fs.DirEntry) {
+		plan.Entries = append(plan.Entries, PlanEntry{Action: PlanActionRemove, From: to, To: from})
+	}, nil)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	if len(plan.Entries) <= 0 {
+		return Plan{}, fmt.Errorf("path %s is not managed", color.BlueString(args.Path))
+	}
+
+	return plan, nil
+}
+
+func removeEmptyDirs(path string, root string) {
+	for dir := filepath.Dir(path); dir != root && core.IsSubpath(dir, root); dir = filepath.Dir(dir) {
+		if err := os.Remove(dir); err != nil {
+			return
+		}
+	}
+}
+
+func (c Commands) Forget(args ForgetArgs) (bool, error) {
+	plan, err := PlanForget(args)
+	if err != nil {
+		return false, err
+	}
+
+	if args.DryRun {
+		c.logPlan(plan, forgetMessages)
+
+		return true, nil
+	}
+
+	ctx := hooksContext{
+		command:          forgetMessages.command,
+		homedir:          args.Extra.Homedir,
+		dotfilesFilesDir: args.Extra.DotfilesFilesDir,
+		home:             func(entry PlanEntry) string { return entry.From },
+	}
+
+	changed, err := c.executePlan(plan, forgetMessages)
+
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	for _, entry := range changed {
+		if layer, ok := core.FindLayer(layers, entry.To); ok {
+			removeEmptyDirs(entry.To, layer.Dir)
+		}
+	}
+
+	return joinPlanErrors(forgetMessages, err, recordState(changed, ctx, args.Extra.StateDir))
+}
@@ -0,0 +1,154 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("forget", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir = filepath.Join(workingDir, "state")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "2"), []byte("foo"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	forget := func(path string, dryRun bool) (bool, error) {
+		return cmd.Forget(commands.ForgetArgs{
+			Path:   path,
+			DryRun: dryRun,
+			Extra: commands.ForgetArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+	}
+
+	It("should remove the dotfiles files and state while keeping ~/ files", func() {
+		result, err := forget(filepath.Join(homedir, ".config", "foo"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "foo"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "2"))).To(BeTrue())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, ".config", "foo", "1"))).To(Equal([]byte("foo")))
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state).To(HaveLen(1))
+		Expect(state).To(HaveKey(filepath.Join(".config", "2")))
+	})
+
+	It("should accept a path under the dotfiles files directory", func() {
+		result, err := forget(filepath.Join(dotfilesFilesDir, ".config", "2"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "2"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "2"))).To(BeTrue())
+	})
+
+	It("should forget a template by its ~/ path", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".gitconfig"), []byte("foo"), 0o600)
+
+		result, err := forget(filepath.Join(homedir, ".gitconfig"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".gitconfig"))).To(BeTrue())
+	})
+
+	It("should not let prune remove forgotten files", func() {
+		forget(filepath.Join(homedir, ".config", "foo"), false)
+
+		result, err := cmd.Prune(commands.PruneArgs{
+			Extra: commands.PruneArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "foo", "1"))).To(BeTrue())
+	})
+
+	It("should only log the removals on dry run", func() {
+		result, err := forget(filepath.Join(homedir, ".config", "2"), true)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(dotfilesFilesDir, ".config", "2"))).To(BeTrue())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 1})
+		Expect(
+			logger.Calls.Lognl[0].Args,
+		).To(Equal([]any{"%s %s", "remove   ", filepath.Join(dotfilesFilesDir, ".config", "2")}))
+	})
+
+	It("should return an error if the path is not managed", func() {
+		os.WriteFile(filepath.Join(homedir, "3"), []byte("foo"), 0o600)
+
+		result, err := forget(filepath.Join(homedir, "3"), false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("path " + filepath.Join(homedir, "3") + " is not managed"))
+	})
+
+	It("should return an error if the path would forget every managed file", func() {
+		result, err := forget(homedir, false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"path " + homedir + " would forget every managed file, pass a subpath instead",
+		))
+	})
+
+	It("should return an error if no path is provided", func() {
+		result, err := forget("", false)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("a path must be provided"))
+	})
+})
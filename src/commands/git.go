@@ -0,0 +1,244 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type GitArgsExtra struct {
+	DotfilesFilesDir string
+}
+
+type GitArgs struct {
+	Args  []string
+	Extra GitArgsExtra
+}
+
+type CommitArgsExtra struct {
+	DotfilesFilesDir string
+	Layers           []core.Layer
+}
+
+type CommitArgs struct {
+	Message string
+	Extra   CommitArgsExtra
+}
+
+type SyncArgs struct {
+	Message string
+	Mode    core.DeployMode
+	Extra   ApplyArgsExtra
+}
+
+const defaultCommitMessage = "Update dotfiles"
+
+func (c Commands) runGit(dir string, args ...string) error {
+	c.Logger.Log("Running git %s ...", color.BlueString(strings.Join(args, " ")))
+
+	output, err := core.RunGit(dir, args...)
+	if err != nil {
+		c.Logger.Lognl(color.RedString(" ✕"))
+		c.logHookOutput(output)
+
+		return errors.Join(fmt.Errorf("git %s failed", color.BlueString(args[0])), err)
+	}
+
+	c.Logger.Lognl(color.GreenString(" ✓"))
+	c.logHookOutput(output)
+
+	return nil
+}
+
+func (c Commands) Git(args GitArgs) (bool, error) {
+	if err := core.ExecGit(core.ResolveRepoDir(args.Extra.DotfilesFilesDir), args.Args...); err != nil {
+		return false, errors.Join(errors.New("error running git"), err)
+	}
+
+	return true, nil
+}
+
+func (c Commands) Commit(args CommitArgs) (bool, error) {
+	repoDir := core.ResolveRepoDir(args.Extra.DotfilesFilesDir)
+	paths := []string{"add", "--all", "--"}
+
+	for _, layer := range resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir) {
+		if fsutil.IsDir(layer.Dir) {
+			relative, err := filepath.Rel(repoDir, layer.Dir)
+			if err != nil {
+				return false, err
+			}
+
+			paths = append(paths, relative)
+		}
+	}
+
+	if err := c.runGit(repoDir, paths...); err != nil {
+		return false, errors.Join(errors.New("error staging changes"), err)
+	}
+
+	staged, err := core.HasStagedChanges(repoDir)
+	if err != nil {
+		return false, errors.Join(errors.New("error checking staged changes"), err)
+	}
+
+	if !staged {
+		c.Logger.Infonl("Nothing to commit")
+
+		return true, nil
+	}
+
+	message := args.Message
+
+	if message == "" {
+		message = defaultCommitMessage
+	}
+
+	if err := c.runGit(repoDir, "commit", "--message", message); err != nil {
+		return false, errors.Join(errors.New("error committing changes"), err)
+	}
+
+	return true, nil
+}
+
+func (c Commands) planSync(args ApplyArgs, adoptArgs AdoptArgs) (Plan, error) {
+	plan, err := PlanApply(args)
+	if err != nil {
+		return Plan{}, err
+	}
+
+	var errorsArr []error
+
+	synced := Plan{IsDir: plan.IsDir}
+
+	for _, entry := range plan.Entries {
+		if entry.Merge != nil && (entry.Merge.Conflict || entry.Action == PlanActionBlocked) {
+			errorsArr = append(errorsArr, fmt.Errorf(
+				"path %s changed both in ~/ and in the dotfiles files, resolve it with apply and adopt first",
+				color.BlueString(entry.To),
+			))
+
+			continue
+		}
+
+		if entry.Action == PlanActionOverwrite &&
+			resolveDrift(args.Extra.StateDir, args.Extra.Homedir, entry.From, entry.To) == core.FileDriftLocal {
+			c.Logger.Infonl("File %s changed locally, adopting it instead", color.BlueString(entry.To))
+
+			continue
+		}
+
+		synced.Entries = append(synced.Entries, entry)
+	}
+
+	if args.Mode != core.DeployModeLink {
+		adoptErrors, err := planSyncAdopt(synced, adoptArgs)
+		if err != nil {
+			return Plan{}, err
+		}
+
+		errorsArr = append(errorsArr, adoptErrors...)
+	}
+
+	if len(errorsArr) > 0 {
+		return Plan{}, errors.Join(append([]error{errors.New("sync aborted")}, errorsArr...)...)
+	}
+
+	return synced, nil
+}
+
+func (c Commands) Sync(args SyncArgs) (bool, error) {
+	repoDir := core.ResolveRepoDir(args.Extra.DotfilesFilesDir)
+
+	clean, err := core.IsGitClean(repoDir)
+	if err != nil {
+		return false, errors.Join(errors.New("error checking repository status"), err)
+	}
+
+	if !clean {
+		return false, fmt.Errorf(
+			"repository %s has uncommitted changes, commit them with %s first",
+			color.BlueString(repoDir),
+			color.MagentaString("dots commit"),
+		)
+	}
+
+	if err := c.runGit(repoDir, "pull", "--rebase"); err != nil {
+		_, _ = core.RunGit(repoDir, "rebase", "--abort")
+
+		return false, errors.Join(errors.New("error pulling, sync aborted"), err)
+	}
+
+	applyArgs := ApplyArgs{From: args.Extra.DotfilesFilesDir, Mode: args.Mode, Extra: args.Extra}
+	adoptArgs := AdoptArgs{
+		From: args.Extra.DotfilesFilesDir,
+		Extra: AdoptArgsExtra{
+			Homedir:          args.Extra.Homedir,
+			DotfilesFilesDir: args.Extra.DotfilesFilesDir,
+			StateDir:         args.Extra.StateDir,
+			Ignore:           args.Extra.Ignore,
+			Layers:           args.Extra.Layers,
+			TemplateData:     args.Extra.TemplateData,
+			Identity:         args.Extra.Identity,
+			Recipients:       args.Extra.Recipients,
+			Hooks:            args.Extra.Hooks,
+			MergeTool:        args.Extra.MergeTool,
+		},
+	}
+
+	plan, err := c.planSync(applyArgs, adoptArgs)
+	if err != nil {
+		return false, err
+	}
+
+	if ok, err := c.applyPlan(plan, applyArgs); !ok {
+		return false, err
+	}
+
+	if args.Mode != core.DeployModeLink {
+		if ok, err := c.Adopt(adoptArgs); !ok {
+			return false, err
+		}
+	}
+
+	return c.Commit(CommitArgs{
+		Message: args.Message,
+		Extra: CommitArgsExtra{
+			DotfilesFilesDir: args.Extra.DotfilesFilesDir,
+			Layers:           args.Extra.Layers,
+		},
+	})
+}
+
+func planSyncAdopt(synced Plan, args AdoptArgs) ([]error, error) {
+	plan, err := PlanAdopt(args)
+	if err != nil {
+		return nil, err
+	}
+
+	var errorsArr []error
+
+	for _, entry := range plan.Entries {
+		if entry.Action != PlanActionBlocked {
+			continue
+		}
+
+		if slices.ContainsFunc(synced.Entries, func(applied PlanEntry) bool { return applied.To == entry.From }) {
+			continue
+		}
+
+		errorsArr = append(errorsArr, fmt.Errorf(
+			"path %s changed in ~/ but is rendered from template %s, update the template first",
+			color.BlueString(entry.From),
+			color.BlueString(entry.To),
+		))
+	}
+
+	return errorsArr, nil
+}
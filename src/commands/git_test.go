@@ -0,0 +1,232 @@
+package commands_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("git", func() {
+	var workingDir string
+	var homedir string
+	var remoteDir string
+	var repoDir string
+	var otherDir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	git := func(dir string, args ...string) string {
+		output, err := exec.Command("git", append([]string{"-C", dir}, args...)...).CombinedOutput()
+
+		Expect(err).To(BeNil(), string(output))
+
+		return strings.TrimSpace(string(output))
+	}
+
+	clone := func(dir string) {
+		git(workingDir, "clone", "--quiet", remoteDir, dir)
+		git(dir, "config", "user.name", "foo")
+		git(dir, "config", "user.email", "foo@example.com")
+		git(dir, "config", "commit.gpgsign", "false")
+	}
+
+	applyArgsExtra := func() commands.ApplyArgsExtra {
+		return commands.ApplyArgsExtra{
+			Homedir:          homedir,
+			DotfilesFilesDir: dotfilesFilesDir,
+			StateDir:         stateDir,
+		}
+	}
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		remoteDir = filepath.Join(workingDir, "remote.git")
+		repoDir = filepath.Join(workingDir, "repo")
+		otherDir = filepath.Join(workingDir, "other")
+		dotfilesFilesDir = filepath.Join(repoDir, "home")
+		stateDir = filepath.Join(workingDir, "state")
+		color.NoColor = true
+
+		git(workingDir, "init", "--quiet", "--bare", remoteDir)
+		clone(repoDir)
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "1"), []byte("foo\nbar\n"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "2"), []byte("foo\n"), 0o600)
+		os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("foo\n"), 0o600)
+
+		git(repoDir, "add", "--all")
+		git(repoDir, "commit", "--quiet", "--message", "Initial")
+		git(repoDir, "push", "--quiet", "--set-upstream", "origin", "HEAD")
+
+		clone(otherDir)
+
+		cmd = commands.Commands{Logger: testing.MakeSpyLogger()}
+		cmd.Apply(commands.ApplyArgs{From: dotfilesFilesDir, Extra: applyArgsExtra()})
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	commit := func(message string) (bool, error) {
+		return cmd.Commit(commands.CommitArgs{
+			Message: message,
+			Extra:   commands.CommitArgsExtra{DotfilesFilesDir: dotfilesFilesDir},
+		})
+	}
+
+	sync := func() (bool, error) {
+		return cmd.Sync(commands.SyncArgs{Message: "Sync", Extra: applyArgsExtra()})
+	}
+
+	pushFromOther := func(path string, content string) {
+		os.WriteFile(filepath.Join(otherDir, "home", path), []byte(content), 0o600)
+		git(otherDir, "commit", "--quiet", "--all", "--message", "Other")
+		git(otherDir, "push", "--quiet")
+	}
+
+	It("should run git in the dotfiles repository root", func() {
+		result, err := cmd.Git(commands.GitArgs{
+			Args:  []string{"tag", "foo"},
+			Extra: commands.GitArgsExtra{DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(git(repoDir, "tag")).To(Equal("foo"))
+	})
+
+	It("should return an error if git fails", func() {
+		result, err := cmd.Git(commands.GitArgs{
+			Args:  []string{"checkout", "missing"},
+			Extra: commands.GitArgsExtra{DotfilesFilesDir: dotfilesFilesDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err.Error()).To(HavePrefix("error running git\n"))
+	})
+
+	It("should stage and commit the dotfiles files changes", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "3"), []byte("baz\n"), 0o600)
+		os.Remove(filepath.Join(dotfilesFilesDir, ".config", "2"))
+		os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("bar\n"), 0o600)
+
+		result, err := commit("Add 3")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(git(repoDir, "log", "-1", "--format=%s")).To(Equal("Add 3"))
+		Expect(git(repoDir, "show", "--name-status", "--format=")).To(Equal(
+			"D\thome/.config/2\nA\thome/.config/3",
+		))
+		Expect(git(repoDir, "status", "--porcelain")).To(Equal("M README.md"))
+	})
+
+	It("should use the default message", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "3"), []byte("foo\n"), 0o600)
+
+		result, err := commit("")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(git(repoDir, "log", "-1", "--format=%s")).To(Equal("Update dotfiles"))
+	})
+
+	It("should log when there is nothing to commit", func() {
+		result, err := commit("foo")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logger.Calls.Infonl).To(Equal([]core.SpyCallNoRt{{Args: []any{"Nothing to commit"}}}))
+		Expect(git(repoDir, "log", "-1", "--format=%s")).To(Equal("Initial"))
+	})
+
+	It("should pull, apply, adopt and commit on sync", func() {
+		pushFromOther(filepath.Join(".config", "1"), "foo\nbaz\n")
+		os.WriteFile(filepath.Join(homedir, ".config", "2"), []byte("bar\n"), 0o600)
+
+		result, err := sync()
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, ".config", "1"))).To(Equal([]byte("foo\nbaz\n")))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, ".config", "2"))).To(Equal([]byte("bar\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, ".config", "2"))).To(Equal([]byte("bar\n")))
+		Expect(git(repoDir, "log", "--format=%s")).To(Equal("Sync\nOther\nInitial"))
+		Expect(git(repoDir, "status", "--porcelain")).To(Equal(""))
+		Expect(logger.Calls.Infonl).To(ContainElement(core.SpyCallNoRt{Args: []any{
+			"File %s changed locally, adopting it instead",
+			filepath.Join(homedir, ".config", "2"),
+		}}))
+	})
+
+	It("should abort sync if the repository has uncommitted changes", func() {
+		os.WriteFile(filepath.Join(repoDir, "README.md"), []byte("bar\n"), 0o600)
+
+		result, err := sync()
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"repository " + repoDir + " has uncommitted changes, commit them with dots commit first",
+		))
+	})
+
+	It("should abort sync on conflicts", func() {
+		pushFromOther(filepath.Join(".config", "1"), "foo\nbaz\n")
+		os.WriteFile(filepath.Join(homedir, ".config", "1"), []byte("foo\nqux\n"), 0o600)
+
+		result, err := sync()
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"sync aborted\npath " + filepath.Join(homedir, ".config", "1") +
+				" changed both in ~/ and in the dotfiles files, resolve it with apply and adopt first",
+		))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, ".config", "1"))).To(Equal([]byte("foo\nqux\n")))
+		Expect(git(repoDir, "log", "-1", "--format=%s")).To(Equal("Other"))
+	})
+
+	It("should abort sync before applying if a locally changed file can not be adopted", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "3.tmpl"), []byte("foo\n"), 0o600)
+		git(repoDir, "add", "--all")
+		git(repoDir, "commit", "--quiet", "--message", "Template")
+		cmd.Apply(commands.ApplyArgs{From: dotfilesFilesDir, Extra: applyArgsExtra()})
+		os.WriteFile(filepath.Join(homedir, ".config", "3"), []byte("bar\n"), 0o600)
+		pushFromOther(filepath.Join(".config", "1"), "foo\nbaz\n")
+
+		result, err := sync()
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"sync aborted\npath " + filepath.Join(homedir, ".config", "3") +
+				" changed in ~/ but is rendered from template " +
+				filepath.Join(dotfilesFilesDir, ".config", "3.tmpl") + ", update the template first",
+		))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, ".config", "1"))).To(Equal([]byte("foo\nbar\n")))
+	})
+
+	It("should abort sync if the pull fails", func() {
+		git(repoDir, "remote", "set-url", "origin", filepath.Join(workingDir, "missing.git"))
+
+		result, err := sync()
+
+		Expect(result).To(BeFalse())
+		Expect(err.Error()).To(HavePrefix("error pulling, sync aborted\ngit pull failed\n"))
+	})
+})
@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type hooksContext struct {
+	command          string
+	homedir          string
+	dotfilesFilesDir string
+	home             func(entry PlanEntry) string
+}
+
+func (h hooksContext) changedPaths(entries []PlanEntry) []string {
+	var changed []string
+
+	for _, entry := range entries {
+		if entry.Action != PlanActionCreate && entry.Action != PlanActionOverwrite &&
+			entry.Action != PlanActionMerge {
+			continue
+		}
+
+		changed = append(changed, relativeTo(h.homedir, h.home(entry)))
+	}
+
+	return changed
+}
+
+func (h hooksContext) source(entry PlanEntry) string {
+	if h.home(entry) == entry.To {
+		return entry.From
+	}
+
+	return entry.To
+}
+
+func (h hooksContext) baseEnv() []string {
+	return []string{
+		"DOTS_TARGET_DIR=" + h.homedir,
+		"DOTS_DOTFILES_FILES_DIR=" + h.dotfilesFilesDir,
+	}
+}
+
+func (h hooksContext) env(stage core.HookStage, changed []string) []string {
+	files := make([]string, 0, len(changed))
+
+	for _, path := range changed {
+		files = append(files, filepath.Join(h.homedir, path))
+	}
+
+	return append(
+		h.baseEnv(),
+		"DOTS_HOOK="+string(stage),
+		"DOTS_CHANGED_FILES="+strings.Join(files, "\n"),
+	)
+}
+
+func (c Commands) logHookOutput(output []byte) {
+	trimmed := strings.TrimRight(string(output), "\n")
+
+	if trimmed == "" {
+		return
+	}
+
+	for line := range strings.SplitSeq(trimmed, "\n") {
+		c.Logger.Lognl("%s", line)
+	}
+}
+
+func (c Commands) logHooks(stage core.HookStage, hooks []core.ConfigHook, changed []string) {
+	for _, hook := range hooks {
+		if !hook.Matches(changed) {
+			continue
+		}
+
+		c.Logger.Lognl(
+			"%s %s %s",
+			color.CyanString("%-9s", "hook"),
+			stage,
+			color.BlueString(hook.Command),
+		)
+	}
+}
+
+func (c Commands) reportRun(ctx hooksContext, action string, source string, err error) {
+	record := core.Record{Command: ctx.command, Action: action, Source: source, Status: "ok"}
+
+	if err != nil {
+		record.Status = "error"
+		record.Error = err.Error()
+	}
+
+	c.report(record)
+}
+
+func (c Commands) runHooks(
+	stage core.HookStage,
+	hooks []core.ConfigHook,
+	entries []PlanEntry,
+	ctx hooksContext,
+) error {
+	var errorsArr []error
+
+	changed := ctx.changedPaths(entries)
+
+	for _, hook := range hooks {
+		if !hook.Matches(changed) {
+			continue
+		}
+
+		c.Logger.Log("Running %s hook %s ...", stage, color.BlueString(hook.Command))
+
+		output, err := core.RunHook(hook, ctx.homedir, ctx.env(stage, changed))
+		if err != nil {
+			c.Logger.Lognl(color.RedString(" ✕"))
+
+			errorsArr = append(errorsArr, errors.Join(
+				fmt.Errorf("hook %s failed", color.BlueString(hook.Command)),
+				err,
+			))
+		} else {
+			c.Logger.Lognl(color.GreenString(" ✓"))
+		}
+
+		c.reportRun(ctx, string(stage), hook.Command, err)
+		c.logHookOutput(output)
+	}
+
+	if len(errorsArr) > 0 {
+		errorsArr = append([]error{fmt.Errorf("error running %s hooks", stage)}, errorsArr...)
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+func joinPlanErrors(messages planMessages, errs ...error) (bool, error) {
+	var errorsArr []error
+
+	for _, err := range errs {
+		if err != nil {
+			errorsArr = append(errorsArr, err)
+		}
+	}
+
+	switch len(errorsArr) {
+	case 0:
+		return true, nil
+	case 1:
+		return false, errorsArr[0]
+	default:
+		return false, errors.Join(append([]error{errors.New(messages.all)}, errorsArr...)...)
+	}
+}
@@ -0,0 +1,223 @@
+package commands_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("hooks", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var output string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		output = filepath.Join(workingDir, "output")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"), []byte("foo"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	record := func(name string) string {
+		return fmt.Sprintf(`echo "%s $DOTS_HOOK $DOTS_CHANGED_FILES" >> %s`, name, output)
+	}
+
+	It("should run pre and post apply hooks scoped to the changed paths", func() {
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PreApply: []core.ConfigHook{{Command: record("pre")}},
+					PostApply: []core.ConfigHook{
+						{Command: record("foo"), Path: ".config/foo"},
+						{Command: record("bar"), Path: ".config/bar"},
+					},
+				},
+			},
+		})
+
+		changed := filepath.Join(homedir, ".config", "foo", "1")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal(
+			"pre pre-apply " + changed + "\nfoo post-apply " + changed + "\n",
+		))
+	})
+
+	It("should not run scoped hooks if nothing changed", func() {
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "1"), []byte("foo"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PostApply: []core.ConfigHook{
+						{Command: record("all")},
+						{Command: record("foo"), Path: ".config/foo"},
+					},
+				},
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal("all post-apply \n"))
+	})
+
+	It("should log hooks output through the logger", func() {
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PostApply: []core.ConfigHook{{Command: "echo foo; echo bar >&2"}},
+				},
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Log: 2, Lognl: 4})
+		Expect(logger.Calls.Log[1].Args).To(Equal([]any{
+			"Running %s hook %s ...",
+			core.HookStagePostApply,
+			"echo foo; echo bar >&2",
+		}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"%s", "foo"}))
+		Expect(logger.Calls.Lognl[3].Args).To(Equal([]any{"%s", "bar"}))
+	})
+
+	It("should not apply files if a pre hook fails", func() {
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PreApply: []core.ConfigHook{{Command: "exit 1"}},
+				},
+			},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("error running pre-apply hooks\nhook exit 1 failed\nexit status 1"))
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "foo", "1"))).To(BeFalse())
+	})
+
+	It("should aggregate post hooks errors with files errors", func() {
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo", "1"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "1", "2"), []byte("foo"), 0o600)
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PostApply: []core.ConfigHook{{Command: "exit 2"}},
+				},
+			},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err.Error()).To(HavePrefix("error applying\nerror applying directory\n"))
+		Expect(err.Error()).To(HaveSuffix("error running post-apply hooks\nhook exit 2 failed\nexit status 2"))
+	})
+
+	It("should only log hooks on dry run", func() {
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:   dotfilesFilesDir,
+			DryRun: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PostApply: []core.ConfigHook{{Command: record("foo"), Path: ".config/foo"}},
+				},
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(output)).To(BeFalse())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 2})
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{
+			"%s %s %s", "hook     ", core.HookStagePostApply, record("foo"),
+		}))
+	})
+
+	It("should run pre and post adopt hooks scoped to the changed paths", func() {
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "1"), []byte("bar"), 0o600)
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From: filepath.Join(homedir, ".config"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PreAdopt:  []core.ConfigHook{{Command: record("pre")}},
+					PostAdopt: []core.ConfigHook{{Command: record("foo"), Path: ".config/foo/1"}},
+				},
+			},
+		})
+
+		changed := filepath.Join(homedir, ".config", "foo", "1")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal(
+			"pre pre-adopt " + changed + "\nfoo post-adopt " + changed + "\n",
+		))
+	})
+
+	It("should report a record per hook", func() {
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Hooks: core.ConfigHooks{
+					PostApply: []core.ConfigHook{{Command: "true"}, {Command: "exit 1"}},
+				},
+			},
+		})
+
+		Expect(reporter.Records[1:]).To(Equal([]core.Record{
+			{Command: "apply", Action: "post-apply", Source: "true", Status: "ok"},
+			{Command: "apply", Action: "post-apply", Source: "exit 1", Status: "error", Error: "exit status 1"},
+		}))
+	})
+})
@@ -0,0 +1,274 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type reviewAnswer string
+
+const (
+	reviewAnswerYes     reviewAnswer = "y"
+	reviewAnswerNo      reviewAnswer = "n"
+	reviewAnswerReverse reviewAnswer = "r"
+	reviewAnswerMerge   reviewAnswer = "m"
+	reviewAnswerQuit    reviewAnswer = "q"
+)
+
+func (c Commands) promptReview(
+	reader *bufio.Reader,
+	entry PlanEntry,
+	messages planMessages,
+	reverse planMessages,
+) (reviewAnswer, error) {
+	if entry.Action == PlanActionRemove {
+		c.Logger.Log("Remove %s? [y]es, [n]o, [q]uit: ", color.BlueString(entry.To))
+	} else {
+		c.Logger.Log(
+			"%s %s to %s? [y]es, [n]o, [r]everse (%s), [m]erge, [q]uit: ",
+			messages.prompt,
+			color.BlueString(entry.From),
+			color.BlueString(entry.To),
+			reverse.command,
+		)
+	}
+
+	line, err := reader.ReadString('\n')
+	if errors.Is(err, io.EOF) && strings.TrimSpace(line) == "" {
+		c.Logger.Lognl("")
+
+		return reviewAnswerQuit, nil
+	}
+
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	answer := strings.ToLower(strings.TrimSpace(line))
+
+	if answer == "" {
+		return reviewAnswerNo, nil
+	}
+
+	return reviewAnswer(answer[:1]), nil
+}
+
+func isReversible(entry PlanEntry) bool {
+	return !entry.Template && !entry.Encrypted && entry.LinkTarget == "" &&
+		!core.IsSymlink(entry.From) && fsutil.IsFile(entry.From) &&
+		!core.IsSymlink(entry.To) && fsutil.IsFile(entry.To)
+}
+
+func (c Commands) logPlanEntryDiff(entry PlanEntry) {
+	if entry.LinkTarget != "" {
+		c.Logger.Lognl(color.GreenString("+symlink to %s", entry.LinkTarget))
+
+		return
+	}
+
+	if entry.Encrypted && core.IsEncrypted(entry.To) {
+		c.Logger.Lognl(color.HiBlackString("encrypted content, diff not shown"))
+
+		return
+	}
+
+	source := entry.Content
+
+	if !entry.Template && !entry.Encrypted && entry.Merge == nil {
+		source = fsutil.ReadFile(entry.From)
+	}
+
+	var current []byte
+
+	if fsutil.IsFile(entry.To) {
+		current = fsutil.ReadFile(entry.To)
+	}
+
+	if fsutil.PathExist(entry.To) && !entry.Encrypted && !core.ModesEqual(entry.From, entry.To) {
+		c.logModeDiff(entry.To, entry.From)
+	}
+
+	c.logUnifiedDiff(udiff.Unified(entry.To, entry.From, string(current), string(source)))
+}
+
+type reviewMerge struct {
+	ctx       hooksContext
+	stateDir  string
+	mergeTool string
+}
+
+func editMerge(merged []byte) ([]byte, error) {
+	file, err := os.CreateTemp("", "dots-merge-*")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.Remove(file.Name())
+
+	_, err = file.Write(merged)
+
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := core.EditFile(core.ResolveEditor(), file.Name()); err != nil {
+		return nil, errors.Join(errors.New("error running editor"), err)
+	}
+
+	return os.ReadFile(file.Name())
+}
+
+func (c Commands) mergePlanEntry(entry PlanEntry, merge reviewMerge) error {
+	home := merge.ctx.home(entry)
+	source := merge.ctx.source(entry)
+	homeContent := fsutil.ReadFile(home)
+	sourceContent := fsutil.ReadFile(source)
+
+	var base []byte
+
+	if merge.stateDir != "" {
+		base, _ = core.ReadBase(merge.stateDir, merge.ctx.homedir, home)
+	}
+
+	merged, conflict := core.Merge3(base, homeContent, home, sourceContent, source)
+
+	if conflict && merge.mergeTool != "" {
+		c.Logger.Infonl("Running merge tool for %s", color.BlueString(home))
+
+		resolved, err := core.RunMergeTool(
+			merge.mergeTool,
+			merge.ctx.homedir,
+			base,
+			homeContent,
+			sourceContent,
+			merged,
+		)
+		if err != nil {
+			c.Logger.Warnnl(
+				"Merge tool failed for %s (%s), opening the editor instead",
+				color.BlueString(home),
+				err.Error(),
+			)
+		} else {
+			merged = resolved
+			conflict = core.HasConflictMarkers(resolved)
+		}
+	}
+
+	if conflict {
+		edited, err := editMerge(merged)
+		if err != nil {
+			return err
+		}
+
+		if core.HasConflictMarkers(edited) {
+			return fmt.Errorf("merge of %s still has conflict markers", color.BlueString(entry.To))
+		}
+
+		merged = edited
+	}
+
+	return os.WriteFile(entry.From, merged, 0o600)
+}
+
+func (c Commands) reviewPlan(
+	plan Plan,
+	messages planMessages,
+	reverse planMessages,
+	merge reviewMerge,
+) (Plan, Plan, error) {
+	if c.Input == nil {
+		return Plan{}, Plan{}, errors.New("interactive mode requires an input")
+	}
+
+	reader := bufio.NewReader(c.Input)
+	reviewed := Plan{IsDir: plan.IsDir}
+	reversed := Plan{IsDir: plan.IsDir}
+	quit := false
+
+	for _, entry := range plan.Entries {
+		if entry.Action == PlanActionUnchanged || entry.Action == PlanActionBlocked {
+			reviewed.Entries = append(reviewed.Entries, entry)
+
+			continue
+		}
+
+		if quit {
+			c.reportPlanEntry(entry, messages, "skipped", nil)
+
+			continue
+		}
+
+		c.Logger.Lognl(
+			"%s %s to %s",
+			formatPlanAction(entry.Action),
+			color.BlueString(entry.From),
+			color.BlueString(entry.To),
+		)
+		c.logPlanEntryDiff(entry)
+
+	prompt:
+		for {
+			answer, err := c.promptReview(reader, entry, messages, reverse)
+			if err != nil {
+				return Plan{}, Plan{}, errors.Join(errors.New("error reading answer"), err)
+			}
+
+			switch answer {
+			case reviewAnswerYes:
+				reviewed.Entries = append(reviewed.Entries, entry)
+
+				break prompt
+			case reviewAnswerNo:
+				c.reportPlanEntry(entry, messages, "skipped", nil)
+
+				break prompt
+			case reviewAnswerQuit:
+				quit = true
+
+				c.reportPlanEntry(entry, messages, "skipped", nil)
+
+				break prompt
+			case reviewAnswerReverse, reviewAnswerMerge:
+				if !isReversible(entry) {
+					c.Logger.Warnnl("Only regular files existing on both sides can be reversed or merged")
+
+					continue
+				}
+
+				if answer == reviewAnswerReverse {
+					reversed.Entries = append(reversed.Entries, makePlanEntry(entry.To, entry.From))
+
+					break prompt
+				}
+
+				if err := c.mergePlanEntry(entry, merge); err != nil {
+					c.Logger.Warnnl("%s", err.Error())
+
+					continue
+				}
+
+				reviewed.Entries = append(reviewed.Entries, makePlanEntry(entry.From, entry.To))
+
+				break prompt
+			default:
+				c.Logger.Warnnl("Unknown answer %s", color.MagentaString(string(answer)))
+			}
+		}
+	}
+
+	return reviewed, reversed, nil
+}
@@ -0,0 +1,197 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("interactive", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var logger *testing.SpyLogger
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+
+		for _, name := range []string{"1", "2", "3"} {
+			os.WriteFile(filepath.Join(dotfilesFilesDir, name), []byte("foo\n"), 0o600)
+			os.WriteFile(filepath.Join(homedir, name), []byte("bar\n"), 0o600)
+		}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	apply := func(input string) (bool, error) {
+		cmd := commands.Commands{Logger: logger, Input: strings.NewReader(input)}
+
+		return cmd.Apply(commands.ApplyArgs{
+			From:        dotfilesFilesDir,
+			Interactive: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+	}
+
+	It("should apply, skip or reverse each file as answered", func() {
+		result, err := apply("y\nn\nr\n")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("foo\n")))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "2"))).To(Equal([]byte("bar\n")))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "3"))).To(Equal([]byte("bar\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, "3"))).To(Equal([]byte("bar\n")))
+	})
+
+	It("should show the diff of each changed file", func() {
+		apply("n\nq\n")
+
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{
+			"%s %s to %s",
+			"overwrite",
+			filepath.Join(dotfilesFilesDir, "1"),
+			filepath.Join(homedir, "1"),
+		}))
+		Expect(logger.Calls.Lognl).To(ContainElement(core.SpyCallNoRt{Args: []any{"-bar"}}))
+		Expect(logger.Calls.Lognl).To(ContainElement(core.SpyCallNoRt{Args: []any{"+foo"}}))
+	})
+
+	It("should skip the remaining files on quit or end of input", func() {
+		result, err := apply("y\n")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("foo\n")))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "2"))).To(Equal([]byte("bar\n")))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "3"))).To(Equal([]byte("bar\n")))
+	})
+
+	It("should ask again on unknown answers", func() {
+		apply("x\ny\nq\n")
+
+		Expect(logger.Calls.Warnnl[0].Args).To(Equal([]any{"Unknown answer %s", "x"}))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("foo\n")))
+	})
+
+	It("should merge both versions with the editor", func() {
+		merged := filepath.Join(workingDir, "merged")
+
+		os.WriteFile(merged, []byte("baz\n"), 0o600)
+		GinkgoT().Setenv("VISUAL", "cp "+merged)
+
+		result, err := apply("m\nq\n")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("baz\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, "1"))).To(Equal([]byte("baz\n")))
+	})
+
+	It("should merge with the recorded base without opening the editor", func() {
+		stateDir, _ := os.MkdirTemp(workingDir, "*")
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("a\nb\nC\n"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("A\nb\nc\n"), 0o600)
+		core.WriteBase(stateDir, homedir, filepath.Join(homedir, "1"), []byte("a\nb\nc\n"))
+		GinkgoT().Setenv("VISUAL", "false")
+
+		cmd := commands.Commands{Logger: logger, Input: strings.NewReader("m\nq\n")}
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:        dotfilesFilesDir,
+			Interactive: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("A\nb\nC\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, "1"))).To(Equal([]byte("A\nb\nC\n")))
+	})
+
+	It("should ask again if the merge still has conflict markers", func() {
+		GinkgoT().Setenv("VISUAL", "true")
+
+		apply("m\nn\nq\n")
+
+		Expect(logger.Calls.Warnnl[0].Args[1]).To(ContainSubstring("still has conflict markers"))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("bar\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, "1"))).To(Equal([]byte("foo\n")))
+	})
+
+	It("should apply in the reverse direction on adopt", func() {
+		cmd := commands.Commands{Logger: logger, Input: strings.NewReader("r\nq\n")}
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From:        homedir,
+			Interactive: true,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("foo\n")))
+		Expect(fsutil.ReadFile(filepath.Join(dotfilesFilesDir, "2"))).To(Equal([]byte("foo\n")))
+	})
+
+	It("should back up and record the files reversed on adopt", func() {
+		stateDir := filepath.Join(workingDir, "state")
+		cmd := commands.Commands{Logger: logger, Input: strings.NewReader("r\nq\n")}
+
+		result, err := cmd.Adopt(commands.AdoptArgs{
+			From:        homedir,
+			Interactive: true,
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+
+		manifests, _ := core.ListBackups(core.ResolveBackupsDir(stateDir))
+
+		Expect(manifests).To(HaveLen(1))
+		Expect(manifests[0].Files).To(HaveLen(1))
+		Expect(manifests[0].Files[0].Path).To(Equal("1"))
+
+		backup := filepath.Join(
+			core.ResolveBackupFilesDir(filepath.Join(core.ResolveBackupsDir(stateDir), manifests[0].Id)),
+			"1",
+		)
+
+		Expect(fsutil.ReadFile(backup)).To(Equal([]byte("bar\n")))
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state).To(HaveKey("1"))
+		Expect(state["1"].Command).To(Equal("apply"))
+	})
+})
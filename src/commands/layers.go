@@ -0,0 +1,50 @@
+package commands
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+func resolveLayers(layers []core.Layer, dotfilesFilesDir string) []core.Layer {
+	if len(layers) <= 0 {
+		return []core.Layer{{Name: "base", Dir: dotfilesFilesDir}}
+	}
+
+	return layers
+}
+
+func loadLayersIgnoreMatchers(
+	layers []core.Layer,
+	patterns []string,
+) (map[string]core.IgnoreMatcher, error) {
+	ignores := map[string]core.IgnoreMatcher{}
+
+	for _, layer := range layers {
+		ignore, err := loadIgnoreMatcher(layer.Dir, patterns)
+		if err != nil {
+			return nil, err
+		}
+
+		ignores[layer.Dir] = ignore
+	}
+
+	return ignores, nil
+}
+
+func relativeTo(root string, path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, root), string(filepath.Separator))
+}
+
+func isOtherLayerDir(layers []core.Layer, layer core.Layer, path string) bool {
+	return path != layer.Dir && core.IsLayerDir(layers, path)
+}
+
+func resolveDestinationLayer(layers []core.Layer, relative string) core.Layer {
+	if layer, ok := core.FindOwnerLayer(layers, relative); ok {
+		return layer
+	}
+
+	return layers[0]
+}
@@ -0,0 +1,80 @@
+package commands
+
+import (
+	"io/fs"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type ManagedArgsExtra struct {
+	Homedir          string
+	DotfilesFilesDir string
+	Ignore           []string
+	Layers           []core.Layer
+}
+
+type ManagedArgs struct {
+	Path  string
+	Extra ManagedArgsExtra
+}
+
+type managedEntry struct {
+	from  string
+	to    string
+	layer string
+}
+
+func (c Commands) Managed(args ManagedArgs) (bool, error) {
+	walk, err := prepareDiffWalk(DiffArgs{
+		FromDir: args.Extra.DotfilesFilesDir,
+		ToDir:   args.Extra.Homedir,
+		Path:    args.Path,
+		Ignore:  args.Extra.Ignore,
+		Layers:  args.Extra.Layers,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var entries []managedEntry
+
+	err = walk.walk(func(args DiffArgs, from string, to string, _ fs.DirEntry) {
+		layer, _ := core.FindLayer(walk.layers, args.FromDir)
+
+		entries = append(entries, managedEntry{from: from, to: to, layer: layer.Name})
+	}, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if len(entries) <= 0 {
+		c.Logger.Infonl("Nothing is managed")
+
+		return true, nil
+	}
+
+	slices.SortStableFunc(entries, func(a managedEntry, b managedEntry) int {
+		return strings.Compare(a.to, b.to)
+	})
+
+	for _, entry := range entries {
+		c.report(core.Record{
+			Command:     "managed",
+			Source:      entry.from,
+			Destination: entry.to,
+			Status:      "managed",
+			Layer:       entry.layer,
+		})
+
+		c.Logger.Lognl(
+			"%s %s",
+			color.BlueString(relativeTo(walk.args.ToDir, entry.to)),
+			color.HiBlackString("(%s)", entry.layer),
+		)
+	}
+
+	return true, nil
+}
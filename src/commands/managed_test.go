@@ -0,0 +1,100 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("managed", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var linuxDir string
+	var logger *testing.SpyLogger
+	var reporter *testing.SpyReporter
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		linuxDir, _ = os.MkdirTemp(workingDir, "*")
+		logger = testing.MakeSpyLogger()
+		reporter = testing.MakeSpyReporter()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger, Reporter: reporter}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(linuxDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(linuxDir, ".config", "2"), []byte("bar"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "3.tmpl"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "4"), []byte("foo"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	managed := func(path string) (bool, error) {
+		return cmd.Managed(commands.ManagedArgs{
+			Path: path,
+			Extra: commands.ManagedArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Layers: []core.Layer{
+					{Name: "base", Dir: dotfilesFilesDir},
+					{Name: "os/linux", Dir: linuxDir},
+				},
+			},
+		})
+	}
+
+	It("should list every managed path with its layer", func() {
+		result, err := managed("")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 3})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s", filepath.Join(".config", "1"), "(base)"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"%s %s", filepath.Join(".config", "2"), "(os/linux)"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"%s %s", "3", "(base)"}))
+		Expect(reporter.Records[1]).To(Equal(core.Record{
+			Command:     "managed",
+			Source:      filepath.Join(linuxDir, ".config", "2"),
+			Destination: filepath.Join(homedir, ".config", "2"),
+			Status:      "managed",
+			Layer:       "os/linux",
+		}))
+	})
+
+	It("should only list the provided path", func() {
+		result, err := managed(filepath.Join(dotfilesFilesDir, ".config", "1"))
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s", filepath.Join(".config", "1"), "(base)"}))
+	})
+
+	It("should log when nothing is managed", func() {
+		os.RemoveAll(filepath.Join(dotfilesFilesDir, ".config"))
+		os.RemoveAll(filepath.Join(linuxDir, ".config"))
+		os.Remove(filepath.Join(dotfilesFilesDir, "3.tmpl"))
+
+		result, err := managed("")
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Nothing is managed"}))
+	})
+})
@@ -0,0 +1,128 @@
+package commands
+
+import (
+	"bytes"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+func planApplyMerge(entry PlanEntry, args ApplyArgs) PlanEntry {
+	if entry.Action != PlanActionOverwrite || entry.Encrypted || entry.LinkTarget != "" ||
+		args.Extra.StateDir == "" || core.IsSymlink(entry.From) || core.IsSymlink(entry.To) {
+		return entry
+	}
+
+	base, ok := core.ReadBase(args.Extra.StateDir, args.Extra.Homedir, entry.To)
+	if !ok {
+		return entry
+	}
+
+	home, err := os.ReadFile(entry.To)
+	if err != nil {
+		return entry
+	}
+
+	source := entry.Content
+
+	if !entry.Template {
+		source = fsutil.ReadFile(entry.From)
+	}
+
+	if core.HasConflictMarkers(home) && !bytes.Equal(home, base) {
+		entry.Action = PlanActionBlocked
+		entry.Merge = &PlanMerge{Base: base, Home: home, Source: source}
+
+		return entry
+	}
+
+	if !core.IsConflict(base, home, source) {
+		return entry
+	}
+
+	merged, conflict := core.Merge3(base, home, entry.To, source, entry.From)
+
+	entry.Action = PlanActionMerge
+	entry.Content = merged
+	entry.Merge = &PlanMerge{Base: base, Home: home, Source: source, Conflict: conflict}
+
+	return entry
+}
+
+func (c Commands) resolveConflicts(plan Plan, mergeTool string, homedir string) Plan {
+	if mergeTool == "" {
+		return plan
+	}
+
+	for index, entry := range plan.Entries {
+		if entry.Merge == nil || !entry.Merge.Conflict {
+			continue
+		}
+
+		c.Logger.Infonl("Running merge tool for %s", color.BlueString(entry.To))
+
+		merged, err := core.RunMergeTool(
+			mergeTool,
+			homedir,
+			entry.Merge.Base,
+			entry.Merge.Home,
+			entry.Merge.Source,
+			entry.Content,
+		)
+		if err != nil {
+			c.Logger.Warnnl(
+				"Merge tool failed for %s (%s), writing conflict markers instead",
+				color.BlueString(entry.To),
+				err.Error(),
+			)
+
+			continue
+		}
+
+		merge := *entry.Merge
+		merge.Conflict = core.HasConflictMarkers(merged)
+
+		entry.Content = merged
+		entry.Merge = &merge
+		plan.Entries[index] = entry
+	}
+
+	return plan
+}
+
+func unchangedEntries(plan Plan) []PlanEntry {
+	var entries []PlanEntry
+
+	for _, entry := range plan.Entries {
+		if entry.Action == PlanActionUnchanged {
+			entries = append(entries, entry)
+		}
+	}
+
+	return entries
+}
+
+func isConflicted(args DiffArgs, from string, to string) bool {
+	if args.StateDir == "" || core.IsEncrypted(from) || core.IsSymlink(from) || core.IsSymlink(to) {
+		return false
+	}
+
+	base, ok := core.ReadBase(args.StateDir, args.ToDir, to)
+	if !ok {
+		return false
+	}
+
+	home, err := os.ReadFile(to)
+	if err != nil {
+		return false
+	}
+
+	source, err := readStatusSource(from, args)
+	if err != nil {
+		return false
+	}
+
+	return core.IsConflict(base, home, source)
+}
@@ -0,0 +1,149 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("merge", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir = filepath.Join(workingDir, "state")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("a\nb\nc\n"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	apply := func(mergeTool string) (bool, error) {
+		return cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+				MergeTool:        mergeTool,
+			},
+		})
+	}
+
+	diverge := func(home string, source string) {
+		os.WriteFile(filepath.Join(homedir, "1"), []byte(home), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte(source), 0o600)
+	}
+
+	It("should record the applied content as the merge base", func() {
+		result, err := apply("")
+
+		base, ok := core.ReadBase(stateDir, homedir, filepath.Join(homedir, "1"))
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(ok).To(BeTrue())
+		Expect(string(base)).To(Equal("a\nb\nc\n"))
+	})
+
+	It("should merge changes made on both sides since the last apply", func() {
+		apply("")
+		diverge("A\nb\nc\n", "a\nb\nC\n")
+
+		result, err := apply("")
+
+		base, _ := core.ReadBase(stateDir, homedir, filepath.Join(homedir, "1"))
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("A\nb\nC\n")))
+		Expect(string(base)).To(Equal("a\nb\nC\n"))
+	})
+
+	It("should write conflict markers and return an error on conflicts", func() {
+		apply("")
+		diverge("a\nB\nc\n", "a\nX\nc\n")
+
+		result, err := apply("")
+
+		base, _ := core.ReadBase(stateDir, homedir, filepath.Join(homedir, "1"))
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"error applying directory\npath " + filepath.Join(homedir, "1") + " has merge conflicts, resolve them and adopt it",
+		))
+		Expect(string(fsutil.ReadFile(filepath.Join(homedir, "1")))).To(Equal(
+			"a\n<<<<<<< " + filepath.Join(homedir, "1") + "\nB\n=======\nX\n>>>>>>> " +
+				filepath.Join(dotfilesFilesDir, "1") + "\nc\n",
+		))
+		Expect(string(base)).To(Equal("a\nb\nc\n"))
+	})
+
+	It("should not merge again while conflict markers are unresolved", func() {
+		apply("")
+		diverge("a\nB\nc\n", "a\nX\nc\n")
+		apply("")
+
+		conflicted := fsutil.ReadFile(filepath.Join(homedir, "1"))
+
+		result, err := apply("")
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(
+			"error applying directory\npath " + filepath.Join(homedir, "1") +
+				" has conflict markers, resolve conflict markers and adopt it first",
+		))
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal(conflicted))
+	})
+
+	It("should resolve conflicts with the merge tool", func() {
+		apply("")
+		diverge("a\nB\nc\n", "a\nX\nc\n")
+
+		result, err := apply(`cat "$LOCAL" > "$MERGED"`)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("a\nB\nc\n")))
+	})
+
+	It("should flag conflicts in diff and status", func() {
+		apply("")
+		diverge("a\nB\nc\n", "a\nX\nc\n")
+
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		cmd.Diff(commands.DiffArgs{FromDir: dotfilesFilesDir, ToDir: homedir, StateDir: stateDir})
+
+		Expect(reporter.Records[0].Status).To(Equal("conflict"))
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+
+		result, err := cmd.Status(commands.StatusArgs{FromDir: dotfilesFilesDir, ToDir: homedir, StateDir: stateDir})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s %s", "C", "1", "(changed locally and in repo)"}))
+	})
+})
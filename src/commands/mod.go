@@ -1,15 +1,53 @@
 package commands
 
-import "github.com/m4rc3l05/dots/src/core"
+import (
+	"io"
+
+	"github.com/m4rc3l05/dots/src/core"
+)
 
 type ICommands interface {
 	Adopt(args AdoptArgs) (bool, error)
 	Diff(args DiffArgs) (bool, error)
+	Status(args StatusArgs) (bool, error)
 	Apply(args ApplyArgs) (bool, error)
+	Restore(args RestoreArgs) (bool, error)
+	CheckIgnore(args CheckIgnoreArgs) (bool, error)
+	Scripts(args ScriptsArgs) (bool, error)
+	Prune(args PruneArgs) (bool, error)
+	Add(args AddArgs) (bool, error)
+	Forget(args ForgetArgs) (bool, error)
+	Managed(args ManagedArgs) (bool, error)
+	Unmanaged(args UnmanagedArgs) (bool, error)
+	Git(args GitArgs) (bool, error)
+	Commit(args CommitArgs) (bool, error)
+	Sync(args SyncArgs) (bool, error)
 }
 
 type Commands struct {
 	ICommands
 
-	Logger core.ILogger
+	Logger   core.ILogger
+	Reporter core.IReporter
+	Input    io.Reader
+}
+
+func (c Commands) report(record core.Record) {
+	if c.Reporter != nil {
+		c.Reporter.Report(record)
+	}
+}
+
+func renderResults[T any](c Commands, results []T, record func(T) core.Record, logText func(T)) {
+	if c.Reporter != nil {
+		for _, result := range results {
+			c.Reporter.Report(record(result))
+		}
+
+		return
+	}
+
+	for _, result := range results {
+		logText(result)
+	}
 }
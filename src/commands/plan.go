@@ -0,0 +1,436 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/aymanbagabas/go-udiff"
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type PlanAction string
+
+const (
+	PlanActionCreate    PlanAction = "create"
+	PlanActionOverwrite PlanAction = "overwrite"
+	PlanActionUnchanged PlanAction = "unchanged"
+	PlanActionBlocked   PlanAction = "blocked"
+	PlanActionMerge     PlanAction = "merge"
+	PlanActionRemove    PlanAction = "remove"
+)
+
+type PlanMerge struct {
+	Base     []byte
+	Home     []byte
+	Source   []byte
+	Conflict bool
+}
+
+type PlanEntry struct {
+	Action     PlanAction
+	From       string
+	To         string
+	LinkTarget string
+	Template   bool
+	Encrypted  bool
+	Content    []byte
+	Merge      *PlanMerge
+}
+
+type Plan struct {
+	IsDir   bool
+	Entries []PlanEntry
+	Drifted []string
+}
+
+type planMessages struct {
+	command  string
+	prompt   string
+	progress string
+	failure  string
+	dir      string
+	all      string
+}
+
+var applyMessages = planMessages{
+	command:  "apply",
+	prompt:   "Apply",
+	progress: "Applying %s to %s ...",
+	failure:  "error applying %s to %s",
+	dir:      "error applying directory",
+	all:      "error applying",
+}
+
+var pruneMessages = planMessages{
+	command: "prune",
+	dir:     "error pruning directory",
+	all:     "error pruning",
+}
+
+var adoptMessages = planMessages{
+	command:  "adopt",
+	prompt:   "Adopt",
+	progress: "Adopting %s to %s ...",
+	failure:  "error adopting %s to %s",
+	dir:      "error adopting directory",
+	all:      "error adopting",
+}
+
+var addMessages = planMessages{
+	command:  "add",
+	prompt:   "Add",
+	progress: "Adding %s to %s ...",
+	failure:  "error adding %s to %s",
+	dir:      "error adding directory",
+	all:      "error adding",
+}
+
+var forgetMessages = planMessages{
+	command: "forget",
+	dir:     "error forgetting directory",
+	all:     "error forgetting",
+}
+
+func makePlanEntry(from string, to string) PlanEntry {
+	if _, err := os.Lstat(to); err != nil {
+		return PlanEntry{Action: PlanActionCreate, From: from, To: to}
+	}
+
+	if !core.IsSymlink(to) && core.FilesEqual(from, to) && core.ModesEqual(from, to) {
+		return PlanEntry{Action: PlanActionUnchanged, From: from, To: to}
+	}
+
+	return PlanEntry{Action: PlanActionOverwrite, From: from, To: to}
+}
+
+func makeSymlinkPlanEntry(from string, to string, target string) PlanEntry {
+	if _, err := os.Lstat(to); err != nil {
+		return PlanEntry{Action: PlanActionCreate, From: from, To: to, LinkTarget: target}
+	}
+
+	if current, err := os.Readlink(to); err == nil && current == target {
+		return PlanEntry{Action: PlanActionUnchanged, From: from, To: to, LinkTarget: target}
+	}
+
+	return PlanEntry{Action: PlanActionOverwrite, From: from, To: to, LinkTarget: target}
+}
+
+func makeContentPlanEntry(from string, to string, content []byte) PlanEntry {
+	entry := PlanEntry{Action: PlanActionOverwrite, From: from, To: to, Content: content}
+
+	if _, err := os.Lstat(to); err != nil {
+		entry.Action = PlanActionCreate
+	} else if !core.IsSymlink(to) && core.ContentEqual(to, content) &&
+		(core.IsEncrypted(from) || core.ModesEqual(from, to)) {
+		entry.Action = PlanActionUnchanged
+	}
+
+	return entry
+}
+
+func isShadowedBySource(path string) bool {
+	if core.IsTemplate(path) || core.IsEncrypted(path) {
+		return false
+	}
+
+	for _, suffix := range []string{core.TemplateSuffix, core.EncryptedSuffix} {
+		if _, err := os.Lstat(path + suffix); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+func planPath(from string, to string, fromRoot string, toRoot string) PlanEntry {
+	if !core.IsSymlink(from) {
+		return makePlanEntry(from, to)
+	}
+
+	target, err := os.Readlink(from)
+	if err != nil {
+		return makePlanEntry(from, to)
+	}
+
+	target = core.MapLinkTarget(target, fromRoot, toRoot)
+
+	if target == to {
+		return PlanEntry{Action: PlanActionUnchanged, From: from, To: to}
+	}
+
+	return makeSymlinkPlanEntry(from, to, target)
+}
+
+func isPlannable(d fs.DirEntry) bool {
+	return d.Type().IsRegular() || d.Type()&fs.ModeSymlink != 0
+}
+
+func loadIgnoreMatcher(root string, patterns []string) (core.IgnoreMatcher, error) {
+	ignore, err := core.LoadIgnoreMatcher(root, patterns)
+	if err != nil {
+		return core.IgnoreMatcher{}, errors.Join(errors.New("error loading ignore rules"), err)
+	}
+
+	return ignore, nil
+}
+
+func isPathIgnored(ignore core.IgnoreMatcher, root string, path string, isDir bool) bool {
+	relative, err := filepath.Rel(root, path)
+	if err != nil || relative == ".." || strings.HasPrefix(relative, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	return ignore.IsIgnored(relative, isDir)
+}
+
+func skipIgnored(d fs.DirEntry) error {
+	if d.IsDir() {
+		return filepath.SkipDir
+	}
+
+	return nil
+}
+
+func formatPlanAction(action PlanAction) string {
+	label := fmt.Sprintf("%-9s", action)
+
+	switch action {
+	case PlanActionCreate:
+		return color.GreenString(label)
+	case PlanActionOverwrite, PlanActionMerge:
+		return color.YellowString(label)
+	case PlanActionBlocked, PlanActionRemove:
+		return color.RedString(label)
+	default:
+		return label
+	}
+}
+
+type planResult struct {
+	entry    PlanEntry
+	messages planMessages
+	status   string
+	err      error
+}
+
+func planRecord(result planResult) core.Record {
+	record := core.Record{
+		Command:     result.messages.command,
+		Action:      string(result.entry.Action),
+		Source:      result.entry.From,
+		Destination: result.entry.To,
+		Status:      result.status,
+	}
+
+	if result.err != nil {
+		record.Error = result.err.Error()
+	}
+
+	return record
+}
+
+func (c Commands) reportPlanEntry(entry PlanEntry, messages planMessages, status string, err error) {
+	c.report(planRecord(planResult{entry: entry, messages: messages, status: status, err: err}))
+}
+
+func (c Commands) logPlannedEntry(result planResult) {
+	entry := result.entry
+
+	if entry.Action == PlanActionRemove {
+		c.Logger.Lognl("%s %s", formatPlanAction(entry.Action), color.BlueString(entry.To))
+
+		return
+	}
+
+	if entry.LinkTarget != "" {
+		c.Logger.Lognl(
+			"%s %s to %s -> %s",
+			formatPlanAction(entry.Action),
+			color.BlueString(entry.From),
+			color.BlueString(entry.To),
+			color.CyanString(entry.LinkTarget),
+		)
+
+		return
+	}
+
+	c.Logger.Lognl(
+		"%s %s to %s",
+		formatPlanAction(entry.Action),
+		color.BlueString(entry.From),
+		color.BlueString(entry.To),
+	)
+}
+
+func (c Commands) logPlan(plan Plan, messages planMessages) {
+	c.Logger.Infonl("Dry run, no files will be changed")
+
+	results := make([]planResult, 0, len(plan.Entries))
+
+	for _, entry := range plan.Entries {
+		results = append(results, planResult{entry: entry, messages: messages, status: "planned"})
+	}
+
+	renderResults(c, results, planRecord, c.logPlannedEntry)
+}
+
+func recreatePlanEntry(entry PlanEntry) error {
+	if entry.Action == PlanActionRemove {
+		return os.Remove(entry.To)
+	}
+
+	if entry.Encrypted && core.IsEncrypted(entry.From) {
+		return core.RecreateDecrypted(entry.From, entry.To, entry.Content)
+	}
+
+	if entry.Template || entry.Encrypted || entry.Merge != nil {
+		return core.RecreateRendered(entry.From, entry.To, entry.Content)
+	}
+
+	if entry.LinkTarget != "" {
+		return core.RecreateSymlink(entry.From, entry.To, entry.LinkTarget)
+	}
+
+	return core.RecreateFile(entry.From, entry.To)
+}
+
+func executePlanEntry(entry PlanEntry, messages planMessages) planResult {
+	result := planResult{entry: entry, messages: messages, status: "ok"}
+
+	switch {
+	case entry.Action == PlanActionUnchanged:
+		return result
+	case entry.Action == PlanActionBlocked && entry.Merge != nil:
+		result.status = "conflict"
+		result.err = fmt.Errorf(
+			"path %s has conflict markers, resolve conflict markers and adopt it first",
+			color.BlueString(entry.To),
+		)
+
+		return result
+	case entry.Action == PlanActionBlocked && entry.Template:
+		result.status = "error"
+		result.err = fmt.Errorf(
+			"path %s is rendered from template %s, update the template instead",
+			color.BlueString(entry.From),
+			color.BlueString(entry.To),
+		)
+
+		return result
+	case entry.Action == PlanActionBlocked:
+		result.status = "error"
+		result.err = fmt.Errorf(
+			"path %s is not a symlink, use --force to replace it",
+			color.BlueString(entry.To),
+		)
+
+		return result
+	}
+
+	if err := recreatePlanEntry(entry); err != nil {
+		failure := fmt.Errorf(messages.failure, color.BlueString(entry.From), color.BlueString(entry.To))
+
+		if entry.Action == PlanActionRemove {
+			failure = fmt.Errorf("error removing %s", color.BlueString(entry.To))
+		}
+
+		result.status = "error"
+		result.err = errors.Join(failure, err)
+
+		return result
+	}
+
+	if entry.Merge != nil && entry.Merge.Conflict {
+		result.status = "conflict"
+		result.err = fmt.Errorf(
+			"path %s has merge conflicts, resolve them and adopt it",
+			color.BlueString(entry.To),
+		)
+	}
+
+	return result
+}
+
+func (c Commands) logPlanResult(result planResult) {
+	entry := result.entry
+
+	if entry.Action == PlanActionRemove {
+		c.Logger.Log("Removing %s ...", color.BlueString(entry.To))
+	} else {
+		c.Logger.Log(result.messages.progress, color.BlueString(entry.From), color.BlueString(entry.To))
+	}
+
+	switch {
+	case result.err == nil && entry.Action == PlanActionUnchanged:
+		c.Logger.Lognl(color.HiBlackString(" = unchanged"))
+	case result.err == nil:
+		c.Logger.Lognl(color.GreenString(" ✓"))
+	default:
+		c.Logger.Lognl(color.RedString(" ✕"))
+
+		if entry.Action == PlanActionBlocked && entry.Template && entry.Merge == nil {
+			c.logUnifiedDiff(udiff.Unified(
+				entry.To,
+				entry.From,
+				string(entry.Content),
+				string(fsutil.ReadFile(entry.From)),
+			))
+		}
+	}
+}
+
+func (c Commands) executePlan(plan Plan, messages planMessages) ([]PlanEntry, error) {
+	var errorsArr []error
+	var changed []PlanEntry
+
+	counts := map[PlanAction]int{}
+	results := make([]planResult, 0, len(plan.Entries))
+
+	for _, entry := range plan.Entries {
+		result := executePlanEntry(entry, messages)
+		results = append(results, result)
+
+		if result.err != nil {
+			errorsArr = append(errorsArr, result.err)
+
+			continue
+		}
+
+		counts[entry.Action] += 1
+
+		if entry.Action != PlanActionUnchanged {
+			changed = append(changed, entry)
+		}
+	}
+
+	renderResults(c, results, planRecord, c.logPlanResult)
+
+	if len(plan.Entries) > 0 {
+		c.Logger.Infonl(
+			"%d created, %d updated, %d unchanged",
+			counts[PlanActionCreate],
+			counts[PlanActionOverwrite]+counts[PlanActionMerge],
+			counts[PlanActionUnchanged],
+		)
+	}
+
+	if counts[PlanActionRemove] > 0 {
+		c.Logger.Infonl("%d removed", counts[PlanActionRemove])
+	}
+
+	if !plan.IsDir && len(errorsArr) > 0 {
+		return changed, errorsArr[0]
+	}
+
+	if len(errorsArr) > 0 {
+		errorsArr = append([]error{errors.New(messages.dir)}, errorsArr...)
+	}
+
+	return changed, errors.Join(errorsArr...)
+}
@@ -0,0 +1,161 @@
+package commands
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type PruneArgsExtra struct {
+	Homedir          string
+	DotfilesFilesDir string
+	StateDir         string
+	Layers           []core.Layer
+}
+
+type PruneArgs struct {
+	Path   string
+	DryRun bool
+	Extra  PruneArgsExtra
+}
+
+func isInScope(relative string, scope string) bool {
+	return scope == "" || relative == scope ||
+		strings.HasPrefix(relative, scope+string(filepath.Separator))
+}
+
+func isLocallyDrifted(fileState core.FileState, home string) bool {
+	drift := fileState.Drift(fileState.Source, home)
+
+	return drift == core.FileDriftLocal || drift == core.FileDriftBoth
+}
+
+func planPrune(
+	layers []core.Layer,
+	homedir string,
+	stateDir string,
+	scope string,
+) ([]PlanEntry, []string, error) {
+	if stateDir == "" {
+		return nil, nil, nil
+	}
+
+	state, err := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error reading files state"), err)
+	}
+
+	var entries []PlanEntry
+	var drifted []string
+
+	for _, relative := range slices.Sorted(maps.Keys(state)) {
+		if !isInScope(relative, scope) {
+			continue
+		}
+
+		if _, err := os.Lstat(state[relative].Source); err == nil {
+			continue
+		}
+
+		if _, ok := core.FindOwnerLayer(layers, relative); ok {
+			continue
+		}
+
+		home := filepath.Join(homedir, relative)
+
+		if _, err := os.Lstat(home); err != nil {
+			continue
+		}
+
+		if isLocallyDrifted(state[relative], home) {
+			drifted = append(drifted, home)
+
+			continue
+		}
+
+		entries = append(entries, PlanEntry{
+			Action: PlanActionRemove,
+			From:   state[relative].Source,
+			To:     home,
+		})
+	}
+
+	return entries, drifted, nil
+}
+
+func (c Commands) warnDriftedPrune(plan Plan) {
+	for _, home := range plan.Drifted {
+		c.Logger.Warnnl(
+			"File %s changed locally since it was last applied, it will not be pruned",
+			color.BlueString(home),
+		)
+	}
+}
+
+func resolvePruneScope(args PruneArgs, layers []core.Layer) (string, error) {
+	if args.Path == "" {
+		return "", nil
+	}
+
+	path, err := filepath.Abs(args.Path)
+	if err != nil {
+		return "", err
+	}
+
+	if layer, ok := core.FindLayer(layers, path); ok {
+		return relativeTo(layer.Dir, path), nil
+	}
+
+	return relativeTo(args.Extra.Homedir, path), nil
+}
+
+func (c Commands) Prune(args PruneArgs) (bool, error) {
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	scope, err := resolvePruneScope(args, layers)
+	if err != nil {
+		return false, err
+	}
+
+	entries, drifted, err := planPrune(layers, args.Extra.Homedir, args.Extra.StateDir, scope)
+	if err != nil {
+		return false, err
+	}
+
+	plan := Plan{IsDir: true, Entries: entries, Drifted: drifted}
+
+	c.warnDriftedPrune(plan)
+
+	if len(plan.Entries) <= 0 {
+		c.Logger.Infonl("Nothing to prune")
+
+		return true, nil
+	}
+
+	if args.DryRun {
+		c.logPlan(plan, pruneMessages)
+
+		return true, nil
+	}
+
+	if err := c.backupPlan(plan, args.Extra.Homedir, args.Extra.StateDir); err != nil {
+		return false, err
+	}
+
+	ctx := hooksContext{
+		command:          pruneMessages.command,
+		homedir:          args.Extra.Homedir,
+		dotfilesFilesDir: args.Extra.DotfilesFilesDir,
+		home:             func(entry PlanEntry) string { return entry.To },
+	}
+
+	changed, err := c.executePlan(plan, pruneMessages)
+
+	return joinPlanErrors(pruneMessages, err, recordState(changed, ctx, args.Extra.StateDir))
+}
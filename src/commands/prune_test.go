@@ -0,0 +1,217 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("prune", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir = filepath.Join(workingDir, "state")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "3"), []byte("foo"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		os.Remove(filepath.Join(dotfilesFilesDir, ".config", "2"))
+		os.Remove(filepath.Join(dotfilesFilesDir, "3"))
+		os.WriteFile(filepath.Join(homedir, "4"), []byte("foo"), 0o600)
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	prune := func(path string, dryRun bool) (bool, error) {
+		return cmd.Prune(commands.PruneArgs{
+			Path:   path,
+			DryRun: dryRun,
+			Extra: commands.PruneArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+	}
+
+	It("should remove and back up files that no longer exist in the dotfiles files", func() {
+		result, err := prune("", false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "1"))).To(BeTrue())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "2"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "4"))).To(BeTrue())
+
+		manifests, _ := core.ListBackups(core.ResolveBackupsDir(stateDir))
+
+		Expect(manifests).To(HaveLen(2))
+		Expect(manifests[1].Files).To(Equal([]core.BackupFile{
+			{Path: filepath.Join(".config", "2")},
+			{Path: "3"},
+		}))
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state).To(HaveLen(1))
+		Expect(state).To(HaveKey(filepath.Join(".config", "1")))
+	})
+
+	It("should remove symlinks applied in link mode that no longer exist in the dotfiles files", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "5"), []byte("foo"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From: filepath.Join(dotfilesFilesDir, "5"),
+			Mode: core.DeployModeLink,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state["5"].LinkTarget).To(Equal(filepath.Join(dotfilesFilesDir, "5")))
+		Expect(state["5"].SourceHash).To(BeEmpty())
+
+		os.Remove(filepath.Join(dotfilesFilesDir, "5"))
+
+		result, err := prune(filepath.Join(homedir, "5"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(core.IsSymlink(filepath.Join(homedir, "5"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeTrue())
+
+		state, _ = core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(state).ToNot(HaveKey("5"))
+	})
+
+	It("should not remove files applied from layers that are not active anymore", func() {
+		workDir := filepath.Join(workingDir, "work")
+
+		os.MkdirAll(filepath.Join(workDir, ".config"), os.ModePerm)
+		os.WriteFile(filepath.Join(workDir, ".config", "work.conf"), []byte("foo"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+				Layers: []core.Layer{
+					{Name: "base", Dir: dotfilesFilesDir},
+					{Name: "profiles/work", Dir: workDir},
+				},
+			},
+		})
+
+		result, err := prune("", false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "work.conf"))).To(BeTrue())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeFalse())
+	})
+
+	It("should warn and keep files that changed locally", func() {
+		os.WriteFile(filepath.Join(homedir, "3"), []byte("bar"), 0o600)
+
+		result, err := prune("", false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "3"))).To(Equal([]byte("bar")))
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "2"))).To(BeFalse())
+		Expect(logger.Calls.Warnnl[0].Args).To(Equal([]any{
+			"File %s changed locally since it was last applied, it will not be pruned",
+			filepath.Join(homedir, "3"),
+		}))
+	})
+
+	It("should only prune the provided path", func() {
+		result, err := prune(filepath.Join(homedir, ".config"), false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "2"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeTrue())
+	})
+
+	It("should only log the removals on dry run", func() {
+		result, err := prune("", true)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeTrue())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 2})
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"%s %s", "remove   ", filepath.Join(homedir, "3")}))
+	})
+
+	It("should log when there is nothing to prune", func() {
+		os.RemoveAll(filepath.Join(homedir, ".config", "2"))
+		os.RemoveAll(filepath.Join(homedir, "3"))
+
+		result, err := prune("", false)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Nothing to prune"}))
+	})
+
+	It("should remove stale files on apply with prune", func() {
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:  dotfilesFilesDir,
+			Prune: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".config", "2"))).To(BeFalse())
+		Expect(fsutil.PathExist(filepath.Join(homedir, "3"))).To(BeFalse())
+		Expect(logger.Calls.Infonl).To(ContainElement(core.SpyCallNoRt{Args: []any{"%d removed", 2}}))
+	})
+})
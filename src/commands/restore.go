@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type RestoreArgsExtra struct {
+	Homedir  string
+	StateDir string
+}
+
+type RestoreArgs struct {
+	Backup string
+	Path   string
+	Extra  RestoreArgsExtra
+}
+
+func (c Commands) listBackups(backupsDir string) (bool, error) {
+	manifests, err := core.ListBackups(backupsDir)
+	if err != nil {
+		return false, errors.Join(errors.New("error listing backups"), err)
+	}
+
+	if len(manifests) <= 0 {
+		c.Logger.Infonl("No backups found")
+
+		return true, nil
+	}
+
+	for _, manifest := range manifests {
+		c.Logger.Lognl(
+			"%s %s (%d file(s))",
+			color.MagentaString(manifest.Id),
+			manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			len(manifest.Files),
+		)
+	}
+
+	return true, nil
+}
+
+func resolveBackupFiles(manifest core.BackupManifest, path string, homedir string) ([]core.BackupFile, error) {
+	if path == "" {
+		return manifest.Files, nil
+	}
+
+	pathFormatted, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if pathFormatted != homedir && !strings.HasPrefix(pathFormatted, homedir+string(filepath.Separator)) {
+		return nil, fmt.Errorf(
+			"path %s is not a subpath of %s",
+			color.BlueString(pathFormatted), color.BlueString(homedir),
+		)
+	}
+
+	relative := strings.TrimPrefix(strings.TrimPrefix(pathFormatted, homedir), string(filepath.Separator))
+
+	var files []core.BackupFile
+
+	for _, file := range manifest.Files {
+		if relative == "" || file.Path == relative ||
+			strings.HasPrefix(file.Path, relative+string(filepath.Separator)) {
+			files = append(files, file)
+		}
+	}
+
+	if len(files) <= 0 {
+		return nil, fmt.Errorf(
+			"path %s is not part of backup %s",
+			color.BlueString(pathFormatted), color.MagentaString(manifest.Id),
+		)
+	}
+
+	return files, nil
+}
+
+func (c Commands) restoreBackupFile(file core.BackupFile, backupFilesDir string, homedir string) error {
+	to := filepath.Join(homedir, file.Path)
+
+	if file.Created {
+		c.Logger.Log("Removing %s ...", color.BlueString(to))
+
+		if err := os.Remove(to); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return errors.Join(fmt.Errorf("error removing %s", color.BlueString(to)), err)
+		}
+
+		return nil
+	}
+
+	from := filepath.Join(backupFilesDir, file.Path)
+
+	c.Logger.Log("Restoring %s to %s ...", color.BlueString(from), color.BlueString(to))
+
+	if err := core.RecreatePath(from, to); err != nil {
+		return errors.Join(fmt.Errorf(
+			"error restoring %s to %s",
+			color.BlueString(from),
+			color.BlueString(to),
+		), err)
+	}
+
+	return nil
+}
+
+func (c Commands) Restore(args RestoreArgs) (bool, error) {
+	backupsDir := core.ResolveBackupsDir(args.Extra.StateDir)
+
+	if args.Backup == "" {
+		return c.listBackups(backupsDir)
+	}
+
+	if args.Backup == "latest" {
+		manifests, err := core.ListBackups(backupsDir)
+		if err != nil {
+			return false, errors.Join(errors.New("error listing backups"), err)
+		}
+
+		if len(manifests) <= 0 {
+			return false, errors.New("no backups found")
+		}
+
+		args.Backup = manifests[len(manifests)-1].Id
+	}
+
+	manifest, err := core.ReadBackupManifest(backupsDir, args.Backup)
+	if err != nil {
+		return false, errors.Join(
+			fmt.Errorf("backup %s does not exists or is not readable", color.MagentaString(args.Backup)),
+			err,
+		)
+	}
+
+	files, err := resolveBackupFiles(manifest, args.Path, args.Extra.Homedir)
+	if err != nil {
+		return false, err
+	}
+
+	backupFilesDir := core.ResolveBackupFilesDir(filepath.Join(backupsDir, manifest.Id))
+
+	var errorsArr []error
+
+	for _, file := range files {
+		if err := c.restoreBackupFile(file, backupFilesDir, args.Extra.Homedir); err != nil {
+			c.Logger.Lognl(color.RedString(" ✕"))
+
+			errorsArr = append(errorsArr, err)
+		} else {
+			c.Logger.Lognl(color.GreenString(" ✓"))
+		}
+	}
+
+	if len(errorsArr) > 0 {
+		errorsArr = append([]error{errors.New("error restoring backup")}, errorsArr...)
+	}
+
+	return len(errorsArr) <= 0, errors.Join(errorsArr...)
+}
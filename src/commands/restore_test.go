@@ -0,0 +1,199 @@
+package commands_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("restore()", func() {
+	var workingDir string
+	var homedir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir, _ = os.MkdirTemp(workingDir, "*")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	makeBackup := func() core.BackupManifest {
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("foo"), 0o644)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "bar"), []byte("bar"), 0o644)
+
+		manifest, _ := core.CreateBackup(
+			core.ResolveBackupsDir(stateDir),
+			homedir,
+			[]core.BackupFile{
+				{Path: filepath.Join(homedir, ".bashrc")},
+				{Path: filepath.Join(homedir, ".config", "foo", "bar")},
+			},
+		)
+
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("changed"), 0o644)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "bar"), []byte("changed"), 0o644)
+
+		return manifest
+	}
+
+	It("should inform when there are no backups", func() {
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Extra: commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"No backups found"}))
+	})
+
+	It("should list available backups", func() {
+		manifest := makeBackup()
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Extra: commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{
+			"%s %s (%d file(s))",
+			manifest.Id,
+			manifest.CreatedAt.Local().Format("2006-01-02 15:04:05"),
+			2,
+		}))
+	})
+
+	It("should return an error if the backup does not exist", func() {
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: "foo",
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(ContainSubstring("backup foo does not exists or is not readable")))
+		testing.AssertSpyLoggerCalls(*logger, nil)
+	})
+
+	It("should restore a full backup", func() {
+		manifest := makeBackup()
+		backupFilesDir := core.ResolveBackupFilesDir(
+			filepath.Join(core.ResolveBackupsDir(stateDir), manifest.Id),
+		)
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: manifest.Id,
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadAll(filepath.Join(homedir, ".bashrc")))).To(Equal("foo"))
+		Expect(string(fsutil.ReadAll(filepath.Join(homedir, ".config", "foo", "bar")))).To(Equal("bar"))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{
+			"Restoring %s to %s ...",
+			filepath.Join(backupFilesDir, ".bashrc"),
+			filepath.Join(homedir, ".bashrc"),
+		}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✓"}))
+	})
+
+	It("should remove the files created after the backup", func() {
+		manifest, _ := core.CreateBackup(
+			core.ResolveBackupsDir(stateDir),
+			homedir,
+			[]core.BackupFile{{Path: filepath.Join(homedir, ".profile"), Created: true}},
+		)
+
+		os.WriteFile(filepath.Join(homedir, ".profile"), []byte("foo"), 0o644)
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: manifest.Id,
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(filepath.Join(homedir, ".profile"))).To(BeFalse())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+		Expect(logger.Calls.Log[0].Args).To(Equal([]any{"Removing %s ...", filepath.Join(homedir, ".profile")}))
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✓"}))
+	})
+
+	It("should restore the latest backup for a sub path", func() {
+		makeBackup()
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: "latest",
+			Path:   filepath.Join(homedir, ".config"),
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadAll(filepath.Join(homedir, ".bashrc")))).To(Equal("changed"))
+		Expect(string(fsutil.ReadAll(filepath.Join(homedir, ".config", "foo", "bar")))).To(Equal("bar"))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 1, Lognl: 1})
+	})
+
+	It("should return an error if the sub path is not part of the backup", func() {
+		manifest := makeBackup()
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: manifest.Id,
+			Path:   filepath.Join(homedir, ".config", "fo"),
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError(fmt.Sprintf(
+			"path %s is not part of backup %s",
+			filepath.Join(homedir, ".config", "fo"),
+			manifest.Id,
+		)))
+		testing.AssertSpyLoggerCalls(*logger, nil)
+	})
+
+	It("should return an error if something happens while restoring", func() {
+		manifest := makeBackup()
+
+		os.Remove(filepath.Join(
+			core.ResolveBackupFilesDir(filepath.Join(core.ResolveBackupsDir(stateDir), manifest.Id)),
+			".bashrc",
+		))
+
+		result, err := cmd.Restore(commands.RestoreArgs{
+			Backup: manifest.Id,
+			Extra:  commands.RestoreArgsExtra{Homedir: homedir, StateDir: stateDir},
+		})
+
+		Expect(result).To(BeFalse())
+		unwrapErrors, _ := err.(interface{ Unwrap() []error })
+		Expect(unwrapErrors.Unwrap()).To(HaveLen(2))
+		Expect(unwrapErrors.Unwrap()[0]).To(MatchError("error restoring backup"))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Log: 2, Lognl: 2})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{" ✕"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{" ✓"}))
+	})
+})
@@ -0,0 +1,223 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type ScriptsArgsExtra struct {
+	StateDir   string
+	ScriptsDir string
+}
+
+type ScriptsArgs struct {
+	Action string
+	Name   string
+	Extra  ScriptsArgsExtra
+}
+
+func loadScripts(scriptsDir string, stateDir string) ([]core.Script, core.ScriptsState, error) {
+	scripts, err := core.FindScripts(scriptsDir)
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error finding scripts"), err)
+	}
+
+	state, err := core.ReadScriptsState(core.ResolveScriptsStateFile(stateDir))
+	if err != nil {
+		return nil, nil, errors.Join(errors.New("error reading scripts state"), err)
+	}
+
+	return scripts, state, nil
+}
+
+func (c Commands) logScripts(scriptsDir string, stateDir string) {
+	scripts, state, err := loadScripts(scriptsDir, stateDir)
+	if err != nil {
+		return
+	}
+
+	for _, script := range scripts {
+		if !script.ShouldRun(state) {
+			continue
+		}
+
+		c.Logger.Lognl(
+			"%s %s %s",
+			color.CyanString("%-9s", "script"),
+			script.Kind,
+			color.BlueString(script.Name),
+		)
+	}
+}
+
+func (c Commands) scriptsStdout() io.Writer {
+	if c.Reporter != nil {
+		return os.Stderr
+	}
+
+	return os.Stdout
+}
+
+func (c Commands) runScripts(scriptsDir string, stateDir string, ctx hooksContext) error {
+	scripts, state, err := loadScripts(scriptsDir, stateDir)
+	if err != nil {
+		return err
+	}
+
+	var errorsArr []error
+
+	ran := 0
+
+	for _, script := range scripts {
+		if !script.ShouldRun(state) {
+			continue
+		}
+
+		c.Logger.Lognl("Running %s script %s ...", script.Kind, color.BlueString(script.Name))
+
+		err := core.ExecHook(script.Hook(), ctx.homedir, append(
+			ctx.baseEnv(),
+			"DOTS_SCRIPT="+script.Name,
+		), c.scriptsStdout())
+		if err != nil {
+			c.Logger.Lognl(color.RedString("✕"))
+
+			errorsArr = append(errorsArr, errors.Join(
+				fmt.Errorf("script %s failed", color.BlueString(script.Name)),
+				err,
+			))
+		} else {
+			ran += 1
+			state[script.Name] = core.ScriptState{Hash: script.Hash, RanAt: time.Now().UTC()}
+
+			c.Logger.Lognl(color.GreenString("✓"))
+		}
+
+		c.reportRun(ctx, "script", script.Name, err)
+	}
+
+	if ran > 0 {
+		if err := core.WriteScriptsState(core.ResolveScriptsStateFile(stateDir), state); err != nil {
+			errorsArr = append(errorsArr, errors.Join(errors.New("error writing scripts state"), err))
+		}
+	}
+
+	if len(errorsArr) > 0 {
+		errorsArr = append([]error{errors.New("error running scripts")}, errorsArr...)
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+func (c Commands) listScripts(args ScriptsArgs) (bool, error) {
+	scripts, state, err := loadScripts(args.Extra.ScriptsDir, args.Extra.StateDir)
+	if err != nil {
+		return false, err
+	}
+
+	if len(scripts) <= 0 && len(state) <= 0 {
+		c.Logger.Infonl("No scripts found")
+
+		return true, nil
+	}
+
+	found := map[string]bool{}
+
+	for _, script := range scripts {
+		found[script.Name] = true
+		ran, ok := state[script.Name]
+
+		switch {
+		case !ok:
+			c.Logger.Lognl(
+				"%s %s %s",
+				color.GreenString("%-9s", "pending"),
+				script.Kind,
+				color.BlueString(script.Name),
+			)
+		case script.ShouldRun(state):
+			c.Logger.Lognl(
+				"%s %s %s (last ran %s)",
+				color.YellowString("%-9s", "changed"),
+				script.Kind,
+				color.BlueString(script.Name),
+				ran.RanAt.Local().Format("2006-01-02 15:04:05"),
+			)
+		default:
+			c.Logger.Lognl(
+				"%s %s %s (ran %s)",
+				color.HiBlackString("%-9s", "ran"),
+				script.Kind,
+				color.BlueString(script.Name),
+				ran.RanAt.Local().Format("2006-01-02 15:04:05"),
+			)
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(state)) {
+		if found[name] {
+			continue
+		}
+
+		ran := state[name]
+
+		c.Logger.Lognl(
+			"%s %s (ran %s)",
+			color.RedString("%-9s", "removed"),
+			color.BlueString(name),
+			ran.RanAt.Local().Format("2006-01-02 15:04:05"),
+		)
+	}
+
+	return true, nil
+}
+
+func (c Commands) resetScripts(args ScriptsArgs) (bool, error) {
+	stateFile := core.ResolveScriptsStateFile(args.Extra.StateDir)
+
+	state, err := core.ReadScriptsState(stateFile)
+	if err != nil {
+		return false, errors.Join(errors.New("error reading scripts state"), err)
+	}
+
+	reset := len(state)
+
+	if args.Name != "" {
+		if _, ok := state[args.Name]; !ok {
+			return false, fmt.Errorf("script %s has no recorded state", color.BlueString(args.Name))
+		}
+
+		delete(state, args.Name)
+
+		reset = 1
+	} else {
+		state = core.ScriptsState{}
+	}
+
+	if err := core.WriteScriptsState(stateFile, state); err != nil {
+		return false, errors.Join(errors.New("error writing scripts state"), err)
+	}
+
+	c.Logger.Infonl("Reset state of %d script(s)", reset)
+
+	return true, nil
+}
+
+func (c Commands) Scripts(args ScriptsArgs) (bool, error) {
+	switch args.Action {
+	case "":
+		return c.listScripts(args)
+	case "reset":
+		return c.resetScripts(args)
+	default:
+		return false, fmt.Errorf("unknown scripts action %s, expected reset", color.MagentaString(args.Action))
+	}
+}
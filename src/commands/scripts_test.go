@@ -0,0 +1,215 @@
+package commands_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("scripts", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var scriptsDir string
+	var stateDir string
+	var output string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		scriptsDir = core.ResolveScriptsDir(workingDir)
+		stateDir = filepath.Join(workingDir, "state")
+		output = filepath.Join(workingDir, "output")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.MkdirAll(scriptsDir, os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("foo"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	writeScript := func(name string, content string) {
+		os.WriteFile(
+			filepath.Join(scriptsDir, name),
+			fmt.Appendf(nil, "echo %s $DOTS_SCRIPT >> %s\n", content, output),
+			0o600,
+		)
+	}
+
+	apply := func(from string) (bool, error) {
+		return cmd.Apply(commands.ApplyArgs{
+			From: from,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+				ScriptsDir:       scriptsDir,
+			},
+		})
+	}
+
+	It("should run once scripts once and on change scripts when they change", func() {
+		writeScript("run_once_foo.sh", "foo")
+		writeScript("run_onchange_bar.sh", "bar")
+
+		result, err := apply(dotfilesFilesDir)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal("foo run_once_foo.sh\nbar run_onchange_bar.sh\n"))
+
+		result, err = apply(dotfilesFilesDir)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal("foo run_once_foo.sh\nbar run_onchange_bar.sh\n"))
+
+		writeScript("run_once_foo.sh", "foo2")
+		writeScript("run_onchange_bar.sh", "bar2")
+
+		result, err = apply(dotfilesFilesDir)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(string(fsutil.ReadFile(output))).To(Equal(
+			"foo run_once_foo.sh\nbar run_onchange_bar.sh\nbar2 run_onchange_bar.sh\n",
+		))
+	})
+
+	It("should not run scripts when applying part of the dotfiles files", func() {
+		writeScript("run_once_foo.sh", "foo")
+
+		result, err := apply(filepath.Join(dotfilesFilesDir, "1"))
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(output)).To(BeFalse())
+	})
+
+	It("should only log pending scripts on dry run", func() {
+		writeScript("run_once_foo.sh", "foo")
+
+		result, err := cmd.Apply(commands.ApplyArgs{
+			From:   dotfilesFilesDir,
+			DryRun: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+				ScriptsDir:       scriptsDir,
+			},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(fsutil.PathExist(output)).To(BeFalse())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1, Lognl: 2})
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{
+			"%s %s %s", "script   ", core.ScriptKindOnce, "run_once_foo.sh",
+		}))
+	})
+
+	It("should not record failed scripts and report the error", func() {
+		os.WriteFile(filepath.Join(scriptsDir, "run_once_foo.sh"), []byte("exit 1\n"), 0o600)
+
+		result, err := apply(dotfilesFilesDir)
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("error running scripts\nscript run_once_foo.sh failed\nexit status 1"))
+		Expect(fsutil.PathExist(core.ResolveScriptsStateFile(stateDir))).To(BeFalse())
+		Expect(fsutil.ReadFile(filepath.Join(homedir, "1"))).To(Equal([]byte("foo")))
+	})
+
+	It("should list the scripts state", func() {
+		writeScript("run_once_foo.sh", "foo")
+		apply(dotfilesFilesDir)
+		writeScript("run_onchange_bar.sh", "bar")
+		core.WriteScriptsState(core.ResolveScriptsStateFile(stateDir), core.ScriptsState{
+			"run_once_foo.sh": {},
+			"run_once_baz.sh": {},
+		})
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+
+		result, err := cmd.Scripts(commands.ScriptsArgs{
+			Extra: commands.ScriptsArgsExtra{StateDir: stateDir, ScriptsDir: scriptsDir},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 3})
+		Expect(logger.Calls.Lognl[0].Args[:4]).To(Equal([]any{
+			"%s %s %s (ran %s)", "ran      ", core.ScriptKindOnce, "run_once_foo.sh",
+		}))
+		Expect(logger.Calls.Lognl[1].Args[:4]).To(Equal([]any{
+			"%s %s %s", "pending  ", core.ScriptKindOnChange, "run_onchange_bar.sh",
+		}))
+		Expect(logger.Calls.Lognl[2].Args[:3]).To(Equal([]any{
+			"%s %s (ran %s)", "removed  ", "run_once_baz.sh",
+		}))
+	})
+
+	It("should reset the state of all scripts or a single one", func() {
+		stateFile := core.ResolveScriptsStateFile(stateDir)
+
+		core.WriteScriptsState(stateFile, core.ScriptsState{"foo": {}, "bar": {}, "baz": {}})
+
+		result, err := cmd.Scripts(commands.ScriptsArgs{
+			Action: "reset",
+			Name:   "foo",
+			Extra:  commands.ScriptsArgsExtra{StateDir: stateDir, ScriptsDir: scriptsDir},
+		})
+
+		state, _ := core.ReadScriptsState(stateFile)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(state).To(Equal(core.ScriptsState{"bar": {}, "baz": {}}))
+
+		result, err = cmd.Scripts(commands.ScriptsArgs{
+			Action: "reset",
+			Extra:  commands.ScriptsArgsExtra{StateDir: stateDir, ScriptsDir: scriptsDir},
+		})
+
+		state, _ = core.ReadScriptsState(stateFile)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(state).To(Equal(core.ScriptsState{}))
+		Expect(logger.Calls.Infonl[1].Args).To(Equal([]any{"Reset state of %d script(s)", 2}))
+	})
+
+	It("should return an error when resetting an unknown script", func() {
+		result, err := cmd.Scripts(commands.ScriptsArgs{
+			Action: "reset",
+			Name:   "foo",
+			Extra:  commands.ScriptsArgsExtra{StateDir: stateDir, ScriptsDir: scriptsDir},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("script foo has no recorded state"))
+	})
+
+	It("should return an error for unknown actions", func() {
+		result, err := cmd.Scripts(commands.ScriptsArgs{Action: "foo"})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(MatchError("unknown scripts action foo, expected reset"))
+	})
+})
@@ -0,0 +1,133 @@
+package commands
+
+import (
+	"errors"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+func isRecordable(entry PlanEntry, home string) bool {
+	return entry.Action != PlanActionBlocked && (core.IsSymlink(home) || fsutil.IsFile(home))
+}
+
+func recordState(entries []PlanEntry, ctx hooksContext, stateDir string) error {
+	if stateDir == "" {
+		return nil
+	}
+
+	stateFile := core.ResolveFilesStateFile(stateDir)
+
+	state, err := core.ReadFilesState(stateFile)
+	if err != nil {
+		return errors.Join(errors.New("error reading files state"), err)
+	}
+
+	var errorsArr []error
+
+	for _, entry := range entries {
+		home := ctx.home(entry)
+
+		if entry.Action == PlanActionRemove {
+			state.Delete(ctx.homedir, home)
+
+			if err := core.RemoveBase(stateDir, ctx.homedir, home); err != nil {
+				errorsArr = append(errorsArr, err)
+			}
+
+			continue
+		}
+
+		if !isRecordable(entry, home) || (entry.Merge != nil && entry.Merge.Conflict) {
+			continue
+		}
+
+		makeState := core.MakeFileState
+
+		if core.IsSymlink(home) {
+			makeState = core.MakeLinkState
+		}
+
+		fileState, err := makeState(ctx.source(entry), home, ctx.command)
+		if err != nil {
+			errorsArr = append(errorsArr, err)
+
+			continue
+		}
+
+		state.Set(ctx.homedir, home, fileState)
+
+		if entry.Encrypted || fileState.LinkTarget != "" {
+			continue
+		}
+
+		base := fsutil.ReadFile(home)
+
+		if entry.Merge != nil {
+			base = entry.Merge.Source
+		}
+
+		if err := core.WriteBase(stateDir, ctx.homedir, home, base); err != nil {
+			errorsArr = append(errorsArr, err)
+		}
+	}
+
+	if err := core.WriteFilesState(stateFile, state); err != nil {
+		errorsArr = append(errorsArr, err)
+	}
+
+	if len(errorsArr) > 0 {
+		errorsArr = append([]error{errors.New("error recording files state")}, errorsArr...)
+	}
+
+	return errors.Join(errorsArr...)
+}
+
+func resolveDrift(stateDir string, homedir string, from string, to string) core.FileDrift {
+	if stateDir == "" {
+		return core.FileDriftNone
+	}
+
+	state, err := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+	if err != nil {
+		return core.FileDriftNone
+	}
+
+	fileState, ok := state.Get(homedir, to)
+	if !ok {
+		return core.FileDriftNone
+	}
+
+	return fileState.Drift(from, to)
+}
+
+func formatDrift(drift core.FileDrift) string {
+	switch drift {
+	case core.FileDriftLocal:
+		return "changed locally"
+	case core.FileDriftRepo:
+		return "changed in repo"
+	case core.FileDriftBoth:
+		return "changed locally and in repo"
+	default:
+		return ""
+	}
+}
+
+func (c Commands) warnLocalChanges(plan Plan, stateDir string, homedir string) {
+	for _, entry := range plan.Entries {
+		if entry.Action != PlanActionOverwrite {
+			continue
+		}
+
+		if resolveDrift(stateDir, homedir, entry.From, entry.To) != core.FileDriftLocal {
+			continue
+		}
+
+		c.Logger.Warnnl(
+			"File %s changed locally since it was last applied, its changes will be overwritten",
+			color.BlueString(entry.To),
+		)
+	}
+}
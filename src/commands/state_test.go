@@ -0,0 +1,130 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("state", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var stateDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		stateDir = filepath.Join(workingDir, "state")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "2"), []byte("foo"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From: dotfilesFilesDir,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		logger = testing.MakeSpyLogger()
+		cmd = commands.Commands{Logger: logger}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should record the hashes and mode of the applied files", func() {
+		state, err := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+
+		Expect(err).To(BeNil())
+		Expect(state).To(HaveLen(2))
+
+		fileState, ok := state.Get(homedir, filepath.Join(homedir, "1"))
+		hash, _ := core.HashFile(filepath.Join(dotfilesFilesDir, "1"))
+
+		Expect(ok).To(BeTrue())
+		Expect(fileState.Source).To(Equal(filepath.Join(dotfilesFilesDir, "1")))
+		Expect(fileState.SourceHash).To(Equal(hash))
+		Expect(fileState.DestinationHash).To(Equal(hash))
+		Expect(fileState.Mode).To(Equal(os.FileMode(0o600)))
+		Expect(fileState.Command).To(Equal("apply"))
+	})
+
+	It("should tell local changes from repo changes in status", func() {
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("bar"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, "2"), []byte("bar"), 0o600)
+
+		result, err := cmd.Status(commands.StatusArgs{FromDir: dotfilesFilesDir, ToDir: homedir, StateDir: stateDir})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s %s", "M", "1", "(changed locally)"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"%s %s %s", "M", "2", "(changed in repo)"}))
+	})
+
+	It("should report the drift in diff records", func() {
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("bar"), 0o600)
+
+		cmd.Diff(commands.DiffArgs{FromDir: dotfilesFilesDir, ToDir: homedir, StateDir: stateDir})
+
+		Expect(reporter.Records[0].Drift).To(Equal("changed-locally"))
+		Expect(reporter.Records[1].Drift).To(Equal(""))
+	})
+
+	It("should warn before overwriting local changes on apply", func() {
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("bar"), 0o600)
+
+		cmd.Apply(commands.ApplyArgs{
+			From:   dotfilesFilesDir,
+			DryRun: true,
+			Extra: commands.ApplyArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		Expect(logger.Calls.Warnnl[0].Args).To(Equal([]any{
+			"File %s changed locally since it was last applied, its changes will be overwritten",
+			filepath.Join(homedir, "1"),
+		}))
+	})
+
+	It("should record adopted files", func() {
+		os.WriteFile(filepath.Join(homedir, "1"), []byte("bar"), 0o600)
+
+		cmd.Adopt(commands.AdoptArgs{
+			From: filepath.Join(homedir, "1"),
+			Extra: commands.AdoptArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				StateDir:         stateDir,
+			},
+		})
+
+		state, _ := core.ReadFilesState(core.ResolveFilesStateFile(stateDir))
+		fileState, _ := state.Get(homedir, filepath.Join(homedir, "1"))
+
+		Expect(fileState.Command).To(Equal("adopt"))
+		Expect(fileState.Drift(filepath.Join(dotfilesFilesDir, "1"), filepath.Join(homedir, "1"))).To(Equal(core.FileDriftNone))
+	})
+})
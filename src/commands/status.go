@@ -0,0 +1,258 @@
+package commands
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type StatusArgs struct {
+	FromDir      string
+	ToDir        string
+	Path         string
+	Mode         core.DeployMode
+	Ignore       []string
+	Layers       []core.Layer
+	TemplateData core.TemplateData
+	Identity     string
+	StateDir     string
+}
+
+type fileStatus string
+
+const (
+	fileStatusInSync      fileStatus = " "
+	fileStatusModified    fileStatus = "M"
+	fileStatusAdded       fileStatus = "A"
+	fileStatusUntracked   fileStatus = "?"
+	fileStatusTypeChanged fileStatus = "T"
+	fileStatusModeChanged fileStatus = "P"
+	fileStatusConflict    fileStatus = "C"
+	fileStatusSkipped     fileStatus = "!"
+)
+
+var fileStatusNames = map[fileStatus]string{
+	fileStatusInSync:      "in-sync",
+	fileStatusModified:    "modified",
+	fileStatusAdded:       "only-in-dotfiles",
+	fileStatusUntracked:   "only-in-home",
+	fileStatusTypeChanged: "type-changed",
+	fileStatusModeChanged: "mode-changed",
+	fileStatusConflict:    "conflict",
+	fileStatusSkipped:     "skipped",
+}
+
+type statusEntry struct {
+	status fileStatus
+	from   string
+	to     string
+	reason string
+	drift  core.FileDrift
+}
+
+func formatFileStatus(status fileStatus) string {
+	switch status {
+	case fileStatusAdded:
+		return color.GreenString(string(status))
+	case fileStatusUntracked, fileStatusConflict:
+		return color.RedString(string(status))
+	case fileStatusModified, fileStatusTypeChanged, fileStatusModeChanged:
+		return color.YellowString(string(status))
+	default:
+		return string(status)
+	}
+}
+
+func readStatusSource(from string, args DiffArgs) ([]byte, error) {
+	switch {
+	case core.IsTemplate(from):
+		return core.RenderTemplate(from, args.TemplateData)
+	case core.IsEncrypted(from):
+		return core.DecryptFile(from, args.Identity)
+	default:
+		return os.ReadFile(from)
+	}
+}
+
+func resolveFileStatus(args DiffArgs, from string, to string, d fs.DirEntry) (fileStatus, error) {
+	toStat, err := os.Lstat(to)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fileStatusAdded, nil
+	}
+
+	if err != nil {
+		return fileStatusSkipped, err
+	}
+
+	isToSymlink := toStat.Mode()&fs.ModeSymlink != 0
+	isContent := core.IsTemplate(from) || core.IsEncrypted(from)
+
+	if !isContent && (args.Mode == core.DeployModeLink || d.Type()&fs.ModeSymlink != 0) {
+		if !isToSymlink {
+			return fileStatusTypeChanged, nil
+		}
+
+		expected := from
+
+		if args.Mode != core.DeployModeLink {
+			target, err := os.Readlink(from)
+			if err != nil {
+				return fileStatusSkipped, err
+			}
+
+			expected = core.MapLinkTarget(target, args.FromDir, args.ToDir)
+		}
+
+		if actual, err := os.Readlink(to); err != nil || actual != expected {
+			return fileStatusModified, nil
+		}
+
+		return fileStatusInSync, nil
+	}
+
+	if !toStat.Mode().IsRegular() {
+		return fileStatusTypeChanged, nil
+	}
+
+	content, err := readStatusSource(from, args)
+	if err != nil {
+		return fileStatusSkipped, err
+	}
+
+	toContent, err := os.ReadFile(to)
+	if err != nil {
+		return fileStatusSkipped, err
+	}
+
+	if !bytes.Equal(content, toContent) {
+		if isConflicted(args, from, to) {
+			return fileStatusConflict, nil
+		}
+
+		return fileStatusModified, nil
+	}
+
+	if !core.IsEncrypted(from) && !core.ModesEqual(from, to) {
+		return fileStatusModeChanged, nil
+	}
+
+	return fileStatusInSync, nil
+}
+
+func statusRecord(entry statusEntry) core.Record {
+	return core.Record{
+		Command:     "status",
+		Source:      entry.from,
+		Destination: entry.to,
+		Status:      fileStatusNames[entry.status],
+		Drift:       string(entry.drift),
+		Error:       entry.reason,
+	}
+}
+
+func (c Commands) logStatusEntry(entry statusEntry, toDir string) {
+	switch entry.status {
+	case fileStatusInSync:
+		return
+	case fileStatusSkipped:
+		c.Logger.Warnnl(
+			"File %s could not be compared (%s), skipping...",
+			color.BlueString(entry.from),
+			entry.reason,
+		)
+
+		return
+	}
+
+	if drift := formatDrift(entry.drift); drift != "" {
+		c.Logger.Lognl(
+			"%s %s %s",
+			formatFileStatus(entry.status),
+			color.BlueString(relativeTo(toDir, entry.to)),
+			color.HiBlackString("(%s)", drift),
+		)
+
+		return
+	}
+
+	c.Logger.Lognl(
+		"%s %s",
+		formatFileStatus(entry.status),
+		color.BlueString(relativeTo(toDir, entry.to)),
+	)
+}
+
+func sortStatusEntries(entries []statusEntry) {
+	slices.SortStableFunc(entries, func(a statusEntry, b statusEntry) int {
+		return strings.Compare(a.to, b.to)
+	})
+}
+
+func (c Commands) Status(args StatusArgs) (bool, error) {
+	walk, err := prepareDiffWalk(DiffArgs{
+		FromDir:      args.FromDir,
+		ToDir:        args.ToDir,
+		Path:         args.Path,
+		Mode:         args.Mode,
+		Ignore:       args.Ignore,
+		Layers:       args.Layers,
+		TemplateData: args.TemplateData,
+		Identity:     args.Identity,
+		StateDir:     args.StateDir,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var tracked []statusEntry
+	var untracked []statusEntry
+
+	err = walk.walk(
+		func(args DiffArgs, from string, to string, d fs.DirEntry) {
+			status, err := resolveFileStatus(args, from, to, d)
+
+			entry := statusEntry{status: status, from: from, to: to}
+
+			if err != nil {
+				entry.reason = err.Error()
+			}
+
+			if status != fileStatusInSync && status != fileStatusAdded && status != fileStatusSkipped {
+				entry.drift = resolveDrift(args.StateDir, args.ToDir, from, to)
+			}
+
+			tracked = append(tracked, entry)
+		},
+		func(path string) {
+			untracked = append(untracked, statusEntry{status: fileStatusUntracked, to: path})
+		},
+	)
+	if err != nil {
+		return false, err
+	}
+
+	sortStatusEntries(tracked)
+	sortStatusEntries(untracked)
+
+	entries := append(tracked, untracked...)
+
+	renderResults(c, entries, statusRecord, func(entry statusEntry) {
+		c.logStatusEntry(entry, walk.args.ToDir)
+	})
+
+	inSync := !slices.ContainsFunc(entries, func(entry statusEntry) bool {
+		return entry.status != fileStatusInSync && entry.status != fileStatusSkipped
+	})
+
+	if inSync {
+		c.Logger.Infonl("Everything is in sync")
+	}
+
+	return inSync, nil
+}
@@ -0,0 +1,159 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("status()", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var logger *testing.SpyLogger
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir, _ = os.MkdirTemp(workingDir, "*")
+		logger = testing.MakeSpyLogger()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config"), os.ModePerm)
+		os.MkdirAll(filepath.Join(homedir, ".config"), os.ModePerm)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should print one line per out of sync file and return false", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "added"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "modified"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "modified"), []byte("bar"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "mode"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "mode"), []byte("foo"), 0o700)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "synced"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "synced"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "type"), []byte("foo"), 0o600)
+		os.Symlink(filepath.Join(homedir, ".config", "synced"), filepath.Join(homedir, ".config", "type"))
+		os.WriteFile(filepath.Join(homedir, ".config", "untracked"), []byte("foo"), 0o600)
+
+		result, err := cmd.Status(commands.StatusArgs{FromDir: dotfilesFilesDir, ToDir: homedir})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 5})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s", "A", ".config/added"}))
+		Expect(logger.Calls.Lognl[1].Args).To(Equal([]any{"%s %s", "P", ".config/mode"}))
+		Expect(logger.Calls.Lognl[2].Args).To(Equal([]any{"%s %s", "M", ".config/modified"}))
+		Expect(logger.Calls.Lognl[3].Args).To(Equal([]any{"%s %s", "T", ".config/type"}))
+		Expect(logger.Calls.Lognl[4].Args).To(Equal([]any{"%s %s", "?", ".config/untracked"}))
+	})
+
+	It("should return true if everything is in sync", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo"), []byte("foo"), 0o600)
+
+		result, err := cmd.Status(commands.StatusArgs{FromDir: dotfilesFilesDir, ToDir: homedir})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Everything is in sync"}))
+	})
+
+	It("should compare rendered templates and symlinks in link mode", func() {
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo.tmpl"), []byte("{{ .OS }}"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo"), []byte("linux"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "bar"), []byte("bar"), 0o600)
+		os.Symlink(filepath.Join(dotfilesFilesDir, ".config", "bar"), filepath.Join(homedir, ".config", "bar"))
+
+		result, err := cmd.Status(commands.StatusArgs{
+			FromDir:      dotfilesFilesDir,
+			ToDir:        homedir,
+			Mode:         core.DeployModeLink,
+			TemplateData: core.TemplateData{OS: "linux"},
+		})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+	})
+
+	It("should only check the provided path and honor ignore rules", func() {
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo", "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "bar"), []byte("bar"), 0o600)
+
+		result, err := cmd.Status(commands.StatusArgs{
+			FromDir: dotfilesFilesDir,
+			ToDir:   homedir,
+			Path:    filepath.Join(dotfilesFilesDir, ".config", "foo"),
+			Ignore:  []string{"2"},
+		})
+
+		Expect(result).To(BeFalse())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1})
+		Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s", "A", ".config/foo/1"}))
+	})
+
+	It("should check a template or an encrypted file scoped by its ~/ path", func() {
+		identityFile := filepath.Join(workingDir, "key.txt")
+		identity, _ := age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()), 0o600)
+
+		encrypted, _ := core.Encrypt([]byte("foo"), identityFile, nil)
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".gitconfig.tmpl"), []byte("{{ .OS }}"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".secret.age"), encrypted, 0o600)
+		os.WriteFile(filepath.Join(homedir, ".gitconfig"), []byte("darwin"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".secret"), []byte("bar"), 0o600)
+
+		for _, name := range []string{".gitconfig", ".secret"} {
+			logger = testing.MakeSpyLogger()
+			cmd = commands.Commands{Logger: logger}
+
+			result, err := cmd.Status(commands.StatusArgs{
+				FromDir:      dotfilesFilesDir,
+				ToDir:        homedir,
+				Path:         filepath.Join(homedir, name),
+				TemplateData: core.TemplateData{OS: "linux"},
+				Identity:     identityFile,
+			})
+
+			Expect(result).To(BeFalse())
+			Expect(err).To(BeNil())
+			testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Lognl: 1})
+			Expect(logger.Calls.Lognl[0].Args).To(Equal([]any{"%s %s", "M", name}))
+		}
+	})
+
+	It("should report a record per file", func() {
+		reporter := testing.MakeSpyReporter()
+		cmd.Reporter = reporter
+
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "foo"), []byte("foo"), 0o600)
+
+		cmd.Status(commands.StatusArgs{FromDir: dotfilesFilesDir, ToDir: homedir})
+
+		Expect(reporter.Records).To(Equal([]core.Record{{
+			Command:     "status",
+			Source:      filepath.Join(dotfilesFilesDir, ".config", "foo"),
+			Destination: filepath.Join(homedir, ".config", "foo"),
+			Status:      "only-in-dotfiles",
+		}}))
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{})
+	})
+})
@@ -0,0 +1,186 @@
+package commands
+
+import (
+	"io/fs"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type UnmanagedArgsExtra struct {
+	Homedir          string
+	DotfilesFilesDir string
+	StateDir         string
+	Ignore           []string
+	Layers           []core.Layer
+	Roots            []string
+}
+
+type UnmanagedArgs struct {
+	Path  string
+	Depth int
+	Extra UnmanagedArgsExtra
+}
+
+var defaultUnmanagedRoots = []string{".config", filepath.Join(".local", "bin"), "."}
+
+func resolveUnmanagedRoots(args UnmanagedArgs, layers []core.Layer) ([]string, error) {
+	if args.Path != "" {
+		root, err := resolveAdoptFrom(args.Path, args.Extra.Homedir, layers)
+		if err != nil {
+			return nil, err
+		}
+
+		return []string{root}, nil
+	}
+
+	roots := args.Extra.Roots
+
+	if len(roots) <= 0 {
+		roots = defaultUnmanagedRoots
+	}
+
+	var resolved []string
+
+	for _, root := range roots {
+		if !filepath.IsAbs(root) {
+			root = filepath.Join(args.Extra.Homedir, root)
+		}
+
+		root = filepath.Clean(root)
+
+		if !core.IsSubpath(root, args.Extra.Homedir) || !fsutil.IsDir(root) ||
+			slices.Contains(resolved, root) {
+			continue
+		}
+
+		resolved = append(resolved, root)
+	}
+
+	return resolved, nil
+}
+
+func isUnmanagedSkipped(path string, layers []core.Layer, stateDir string) bool {
+	if _, ok := core.FindLayer(layers, path); ok {
+		return true
+	}
+
+	return stateDir != "" && core.IsSubpath(path, stateDir)
+}
+
+func walkUnmanaged(
+	root string,
+	args UnmanagedArgs,
+	layers []core.Layer,
+	ignores map[string]core.IgnoreMatcher,
+	visit func(path string, isDir bool),
+) error {
+	homedir := args.Extra.Homedir
+	topLevel := root == homedir
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if path == root {
+			return nil
+		}
+
+		if isUnmanagedSkipped(path, layers, args.Extra.StateDir) {
+			return skipIgnored(d)
+		}
+
+		relative := relativeTo(homedir, path)
+		layer := resolveDestinationLayer(layers, relative)
+
+		if isPathIgnored(ignores[layer.Dir], layer.Dir, filepath.Join(layer.Dir, relative), d.IsDir()) {
+			return skipIgnored(d)
+		}
+
+		if topLevel {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+
+			if !strings.HasPrefix(d.Name(), ".") {
+				return nil
+			}
+		}
+
+		if d.IsDir() {
+			if _, ok := core.FindOwnerLayer(layers, relative); !ok {
+				visit(path, true)
+
+				return filepath.SkipDir
+			}
+
+			if args.Depth > 0 && strings.Count(relativeTo(root, path), string(filepath.Separator))+1 >= args.Depth {
+				return filepath.SkipDir
+			}
+
+			return nil
+		}
+
+		if !isPlannable(d) {
+			return nil
+		}
+
+		if _, ok := core.FindOwnerLayer(layers, relative); ok {
+			return nil
+		}
+
+		visit(path, false)
+
+		return nil
+	})
+}
+
+func (c Commands) Unmanaged(args UnmanagedArgs) (bool, error) {
+	layers := resolveLayers(args.Extra.Layers, args.Extra.DotfilesFilesDir)
+
+	roots, err := resolveUnmanagedRoots(args, layers)
+	if err != nil {
+		return false, err
+	}
+
+	ignores, err := loadLayersIgnoreMatchers(layers, args.Extra.Ignore)
+	if err != nil {
+		return false, err
+	}
+
+	count := 0
+
+	for _, root := range roots {
+		err := walkUnmanaged(root, args, layers, ignores, func(path string, isDir bool) {
+			count += 1
+
+			c.report(core.Record{Command: "unmanaged", Destination: path, Status: "unmanaged"})
+
+			relative := relativeTo(args.Extra.Homedir, path)
+
+			if isDir {
+				relative += string(filepath.Separator)
+			}
+
+			c.Logger.Lognl("%s", color.BlueString(relative))
+		})
+		if err != nil {
+			return false, err
+		}
+	}
+
+	if count <= 0 {
+		c.Logger.Infonl("Nothing unmanaged found")
+
+		return true, nil
+	}
+
+	c.Logger.Infonl("%d unmanaged", count)
+
+	return true, nil
+}
@@ -0,0 +1,148 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/commands"
+	"github.com/m4rc3l05/dots/src/core"
+	"github.com/m4rc3l05/dots/src/testing"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("unmanaged", func() {
+	var workingDir string
+	var homedir string
+	var dotfilesFilesDir string
+	var logger *testing.SpyLogger
+	var reporter *testing.SpyReporter
+	var cmd commands.Commands
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir, _ = os.MkdirTemp(workingDir, "*")
+		dotfilesFilesDir = filepath.Join(homedir, ".dotfiles", "home")
+		logger = testing.MakeSpyLogger()
+		reporter = testing.MakeSpyReporter()
+		color.NoColor = true
+		cmd = commands.Commands{Logger: logger, Reporter: reporter}
+
+		os.MkdirAll(filepath.Join(dotfilesFilesDir, ".config", "nvim", "lua"), os.ModePerm)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "nvim", "init.lua"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".config", "nvim", "lua", "1.lua"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(dotfilesFilesDir, ".zshrc"), []byte("foo"), 0o600)
+
+		os.MkdirAll(filepath.Join(homedir, ".config", "nvim", "lua"), os.ModePerm)
+		os.MkdirAll(filepath.Join(homedir, ".config", "kitty"), os.ModePerm)
+		os.MkdirAll(filepath.Join(homedir, ".local", "bin"), os.ModePerm)
+		os.MkdirAll(filepath.Join(homedir, ".cache", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".config", "nvim", "init.lua"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "nvim", "lazy.json"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "nvim", "lua", "1.lua"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "nvim", "lua", "2.lua"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".config", "kitty", "kitty.conf"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".local", "bin", "foo"), []byte("foo"), 0o700)
+		os.WriteFile(filepath.Join(homedir, ".cache", "foo", "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".zshrc"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(homedir, "notes.txt"), []byte("foo"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	unmanaged := func(path string, depth int, ignore []string, roots []string) (bool, error) {
+		return cmd.Unmanaged(commands.UnmanagedArgs{
+			Path:  path,
+			Depth: depth,
+			Extra: commands.UnmanagedArgsExtra{
+				Homedir:          homedir,
+				DotfilesFilesDir: dotfilesFilesDir,
+				Ignore:           ignore,
+				Roots:            roots,
+			},
+		})
+	}
+
+	logged := func() []string {
+		var paths []string
+
+		for _, call := range logger.Calls.Lognl {
+			paths = append(paths, call.Args[1].(string))
+		}
+
+		return paths
+	}
+
+	It("should list unmanaged files in the default roots", func() {
+		result, err := unmanaged("", 0, nil, nil)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logged()).To(Equal([]string{
+			filepath.Join(".config", "kitty") + "/",
+			filepath.Join(".config", "nvim", "lazy.json"),
+			filepath.Join(".config", "nvim", "lua", "2.lua"),
+			filepath.Join(".local", "bin", "foo"),
+			".bashrc",
+		}))
+		Expect(logger.Calls.Infonl).To(Equal([]core.SpyCallNoRt{{Args: []any{"%d unmanaged", 5}}}))
+		Expect(reporter.Records[0]).To(Equal(core.Record{
+			Command:     "unmanaged",
+			Destination: filepath.Join(homedir, ".config", "kitty"),
+			Status:      "unmanaged",
+		}))
+	})
+
+	It("should not walk managed directories deeper than the depth", func() {
+		result, err := unmanaged("", 2, nil, nil)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logged()).NotTo(ContainElement(filepath.Join(".config", "nvim", "lua", "2.lua")))
+		Expect(logged()).To(ContainElement(filepath.Join(".config", "nvim", "lazy.json")))
+	})
+
+	It("should honor ignore rules", func() {
+		result, err := unmanaged("", 0, []string{"kitty/", "*.json"}, nil)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logged()).To(Equal([]string{
+			filepath.Join(".config", "nvim", "lua", "2.lua"),
+			filepath.Join(".local", "bin", "foo"),
+			".bashrc",
+		}))
+	})
+
+	It("should walk the configured roots", func() {
+		result, err := unmanaged("", 0, nil, []string{".cache", "missing"})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logged()).To(Equal([]string{filepath.Join(".cache", "foo") + "/"}))
+	})
+
+	It("should only walk the provided path", func() {
+		result, err := unmanaged(filepath.Join(homedir, ".config", "nvim"), 0, nil, nil)
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(logged()).To(Equal([]string{
+			filepath.Join(".config", "nvim", "lazy.json"),
+			filepath.Join(".config", "nvim", "lua", "2.lua"),
+		}))
+	})
+
+	It("should log when nothing is unmanaged", func() {
+		result, err := unmanaged("", 0, nil, []string{filepath.Join(".config", "nvim", "lua", "missing")})
+
+		Expect(result).To(BeTrue())
+		Expect(err).To(BeNil())
+		testing.AssertSpyLoggerCalls(*logger, &testing.SpyLoggerCallNumber{Infonl: 1})
+		Expect(logger.Calls.Infonl[0].Args).To(Equal([]any{"Nothing unmanaged found"}))
+	})
+})
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+const backupIdLayout = "20060102T150405.000000000Z"
+
+type BackupFile struct {
+	Path       string `json:"path"`
+	ReplacedBy string `json:"replacedBy"`
+	Created    bool   `json:"created,omitempty"`
+}
+
+type BackupManifest struct {
+	Id        string       `json:"id"`
+	CreatedAt time.Time    `json:"createdAt"`
+	Homedir   string       `json:"homedir"`
+	Files     []BackupFile `json:"files"`
+}
+
+func ResolveStateDir(homedir string) string {
+	stateHome := genEnvOrNil("XDG_STATE_HOME")
+
+	if stateHome == nil || !filepath.IsAbs(*stateHome) {
+		return filepath.Join(homedir, ".local", "state", "dots")
+	}
+
+	return filepath.Join(*stateHome, "dots")
+}
+
+func ResolveBackupsDir(stateDir string) string {
+	return filepath.Join(stateDir, "backups")
+}
+
+func ResolveBackupFilesDir(backupDir string) string {
+	return filepath.Join(backupDir, "home")
+}
+
+func CreateBackup(
+	backupsDir string,
+	homedir string,
+	files []BackupFile,
+) (BackupManifest, error) {
+	now := time.Now().UTC()
+	manifest := BackupManifest{
+		Id:        now.Format(backupIdLayout),
+		CreatedAt: now,
+		Homedir:   homedir,
+		Files:     []BackupFile{},
+	}
+	backupDir := filepath.Join(backupsDir, manifest.Id)
+
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
+		return BackupManifest{}, err
+	}
+
+	for _, file := range files {
+		path := strings.TrimPrefix(strings.TrimPrefix(file.Path, homedir), string(filepath.Separator))
+
+		if !file.Created {
+			if err := RecreatePath(
+				file.Path,
+				filepath.Join(ResolveBackupFilesDir(backupDir), path),
+			); err != nil {
+				return BackupManifest{}, err
+			}
+		}
+
+		manifest.Files = append(
+			manifest.Files,
+			BackupFile{Path: path, ReplacedBy: file.ReplacedBy, Created: file.Created},
+		)
+	}
+
+	data, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return BackupManifest{}, err
+	}
+
+	if err := os.WriteFile(filepath.Join(backupDir, "manifest.json"), data, 0o600); err != nil {
+		return BackupManifest{}, err
+	}
+
+	return manifest, nil
+}
+
+func ReadBackupManifest(backupsDir string, id string) (BackupManifest, error) {
+	data, err := os.ReadFile(filepath.Join(backupsDir, id, "manifest.json"))
+	if err != nil {
+		return BackupManifest{}, err
+	}
+
+	var manifest BackupManifest
+
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return BackupManifest{}, err
+	}
+
+	return manifest, nil
+}
+
+func ListBackups(backupsDir string) ([]BackupManifest, error) {
+	entries, err := os.ReadDir(backupsDir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []BackupManifest{}, nil
+		}
+
+		return nil, err
+	}
+
+	manifests := []BackupManifest{}
+
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		manifest, err := ReadBackupManifest(backupsDir, entry.Name())
+		if err != nil {
+			continue
+		}
+
+		manifests = append(manifests, manifest)
+	}
+
+	slices.SortFunc(manifests, func(a BackupManifest, b BackupManifest) int {
+		return strings.Compare(a.Id, b.Id)
+	})
+
+	return manifests, nil
+}
@@ -0,0 +1,105 @@
+package core_test
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/gookit/goutil/fsutil"
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResolveStateDir()", func() {
+	It("should use `XDG_STATE_HOME` if defined", func() {
+		GinkgoT().Setenv("XDG_STATE_HOME", "/foo/state")
+
+		Expect(core.ResolveStateDir("/home/foo")).To(Equal("/foo/state/dots"))
+	})
+
+	It("should fallback to `~/.local/state` if `XDG_STATE_HOME` is not defined", func() {
+		GinkgoT().Setenv("XDG_STATE_HOME", "")
+
+		Expect(core.ResolveStateDir("/home/foo")).To(Equal("/home/foo/.local/state/dots"))
+	})
+})
+
+var _ = Describe("CreateBackup()", func() {
+	It("should backup files mirroring the home layout", func() {
+		homedir := filepath.Join(workingDir, "home")
+		backupsDir := filepath.Join(workingDir, "backups")
+
+		os.MkdirAll(filepath.Join(homedir, ".config", "foo"), os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("foo"), 0o644)
+		os.WriteFile(filepath.Join(homedir, ".config", "foo", "bar"), []byte("bar"), 0o644)
+
+		manifest, err := core.CreateBackup(backupsDir, homedir, []core.BackupFile{
+			{Path: filepath.Join(homedir, ".bashrc"), ReplacedBy: "/dotfiles/.bashrc"},
+			{Path: filepath.Join(homedir, ".config", "foo", "bar"), ReplacedBy: "/dotfiles/.config/foo/bar"},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(manifest.Homedir).To(Equal(homedir))
+		Expect(manifest.Files).To(Equal([]core.BackupFile{
+			{Path: ".bashrc", ReplacedBy: "/dotfiles/.bashrc"},
+			{Path: ".config/foo/bar", ReplacedBy: "/dotfiles/.config/foo/bar"},
+		}))
+
+		backupFilesDir := core.ResolveBackupFilesDir(filepath.Join(backupsDir, manifest.Id))
+
+		Expect(string(fsutil.ReadAll(filepath.Join(backupFilesDir, ".bashrc")))).To(Equal("foo"))
+		Expect(
+			string(fsutil.ReadAll(filepath.Join(backupFilesDir, ".config", "foo", "bar"))),
+		).To(Equal("bar"))
+
+		var written core.BackupManifest
+
+		data, _ := os.ReadFile(filepath.Join(backupsDir, manifest.Id, "manifest.json"))
+		json.Unmarshal(data, &written)
+
+		Expect(written.Id).To(Equal(manifest.Id))
+		Expect(written.Files).To(Equal(manifest.Files))
+	})
+
+	It("should return an error if a file can not be backed up", func() {
+		homedir := filepath.Join(workingDir, "home")
+
+		_, err := core.CreateBackup(filepath.Join(workingDir, "backups"), homedir, []core.BackupFile{
+			{Path: filepath.Join(homedir, ".bashrc")},
+		})
+
+		Expect(err).To(MatchError(ContainSubstring("no such file or directory")))
+	})
+})
+
+var _ = Describe("ListBackups()", func() {
+	It("should return no backups if the backups dir does not exist", func() {
+		manifests, err := core.ListBackups(filepath.Join(workingDir, "backups"))
+
+		Expect(err).To(BeNil())
+		Expect(manifests).To(BeEmpty())
+	})
+
+	It("should list backups sorted by creation", func() {
+		homedir := filepath.Join(workingDir, "home")
+		backupsDir := filepath.Join(workingDir, "backups")
+
+		os.MkdirAll(homedir, os.ModePerm)
+		os.WriteFile(filepath.Join(homedir, ".bashrc"), []byte("foo"), 0o644)
+
+		first, _ := core.CreateBackup(backupsDir, homedir, []core.BackupFile{
+			{Path: filepath.Join(homedir, ".bashrc")},
+		})
+		second, _ := core.CreateBackup(backupsDir, homedir, []core.BackupFile{
+			{Path: filepath.Join(homedir, ".bashrc")},
+		})
+
+		manifests, err := core.ListBackups(backupsDir)
+
+		Expect(err).To(BeNil())
+		Expect(manifests).To(HaveLen(2))
+		Expect(manifests[0].Id).To(Equal(first.Id))
+		Expect(manifests[1].Id).To(Equal(second.Id))
+	})
+})
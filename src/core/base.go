@@ -0,0 +1,52 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+func ResolveBasesDir(stateDir string) string {
+	return filepath.Join(stateDir, "bases")
+}
+
+func ResolveBaseFile(stateDir string, homedir string, path string) string {
+	relative := strings.TrimPrefix(strings.TrimPrefix(path, homedir), string(filepath.Separator))
+
+	return filepath.Join(ResolveBasesDir(stateDir), relative)
+}
+
+func ReadBase(stateDir string, homedir string, path string) ([]byte, bool) {
+	content, err := os.ReadFile(ResolveBaseFile(stateDir, homedir, path))
+	if err != nil {
+		return nil, false
+	}
+
+	return content, true
+}
+
+func WriteBase(stateDir string, homedir string, path string, content []byte) error {
+	file := ResolveBaseFile(stateDir, homedir, path)
+
+	if err := os.MkdirAll(filepath.Dir(file), 0o700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(file, content, 0o600)
+}
+
+func RemoveBase(stateDir string, homedir string, path string) error {
+	err := os.Remove(ResolveBaseFile(stateDir, homedir, path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+
+	return err
+}
+
+func IsConflict(base []byte, home []byte, source []byte) bool {
+	return !bytes.Equal(base, home) && !bytes.Equal(base, source) && !bytes.Equal(home, source)
+}
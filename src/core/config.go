@@ -0,0 +1,226 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+type ConfigHook struct {
+	Command string `toml:"command" yaml:"command"`
+	Path    string `toml:"path"    yaml:"path"`
+}
+
+type ConfigHooks struct {
+	PreApply  []ConfigHook `toml:"preApply"  yaml:"preApply"`
+	PostApply []ConfigHook `toml:"postApply" yaml:"postApply"`
+	PreAdopt  []ConfigHook `toml:"preAdopt"  yaml:"preAdopt"`
+	PostAdopt []ConfigHook `toml:"postAdopt" yaml:"postAdopt"`
+}
+
+type Config struct {
+	FilesDir       string         `toml:"filesDir"       yaml:"filesDir"`
+	TargetDir      string         `toml:"targetDir"      yaml:"targetDir"`
+	Ignore         []string       `toml:"ignore"         yaml:"ignore"`
+	Mode           string         `toml:"mode"           yaml:"mode"`
+	Profiles       []string       `toml:"profiles"       yaml:"profiles"`
+	Identity       string         `toml:"identity"       yaml:"identity"`
+	Recipients     []string       `toml:"recipients"     yaml:"recipients"`
+	MergeTool      string         `toml:"mergeTool"      yaml:"mergeTool"`
+	UnmanagedRoots []string       `toml:"unmanagedRoots" yaml:"unmanagedRoots"`
+	Hooks          ConfigHooks    `toml:"hooks"          yaml:"hooks"`
+	Vars           map[string]any `toml:"vars"           yaml:"vars"`
+}
+
+type SettingSource string
+
+const (
+	SettingSourceFlag    SettingSource = "flag"
+	SettingSourceEnv     SettingSource = "env"
+	SettingSourceConfig  SettingSource = "config"
+	SettingSourceDefault SettingSource = "default"
+)
+
+type Setting struct {
+	Value  string
+	Source SettingSource
+}
+
+type SettingsFlags struct {
+	DotfilesFilesDir *string
+	TargetDir        *string
+	Mode             *string
+	Profile          *string
+	Identity         *string
+}
+
+type Settings struct {
+	Homedir          string
+	ConfigFile       string
+	DotfilesFilesDir Setting
+	TargetDir        Setting
+	Mode             Setting
+	Profiles         Setting
+	Identity         Setting
+	MergeTool        Setting
+	Layers           []Layer
+	Config           Config
+}
+
+var configFileNames = []string{"dots.toml", "dots.yaml", "dots.yml"}
+
+func ResolveConfigDir(homedir string) string {
+	configHome := genEnvOrNil("XDG_CONFIG_HOME")
+
+	if configHome == nil || !filepath.IsAbs(*configHome) {
+		return filepath.Join(homedir, ".config", "dots")
+	}
+
+	return filepath.Join(*configHome, "dots")
+}
+
+func FindConfigFile(dirs ...string) string {
+	for _, dir := range dirs {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+
+			if stat, err := os.Stat(path); err == nil && stat.Mode().IsRegular() {
+				return path
+			}
+		}
+	}
+
+	return ""
+}
+
+func ReadConfig(path string) (Config, error) {
+	var config Config
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if filepath.Ext(path) == ".toml" {
+		metadata, err := toml.Decode(string(data), &config)
+		if err != nil {
+			return Config{}, err
+		}
+
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return Config{}, fmt.Errorf("unknown config key %s", undecoded[0].String())
+		}
+
+		return config, nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		return Config{}, err
+	}
+
+	return config, nil
+}
+
+func resolveConfigPath(path string, configDir string, homedir string) string {
+	if path == "" {
+		return ""
+	}
+
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		return filepath.Join(homedir, strings.TrimPrefix(path, "~"))
+	}
+
+	if !filepath.IsAbs(path) {
+		return filepath.Join(configDir, path)
+	}
+
+	return path
+}
+
+func resolveSetting(flag *string, env string, config string, fallback string) Setting {
+	if flag != nil {
+		return Setting{Value: *flag, Source: SettingSourceFlag}
+	}
+
+	if fromEnv := genEnvOrNil(env); fromEnv != nil {
+		return Setting{Value: *fromEnv, Source: SettingSourceEnv}
+	}
+
+	if config != "" {
+		return Setting{Value: config, Source: SettingSourceConfig}
+	}
+
+	return Setting{Value: fallback, Source: SettingSourceDefault}
+}
+
+func ResolveSettings(homedir string, flags SettingsFlags) (Settings, error) {
+	settings := Settings{Homedir: homedir}
+	dotfilesFilesDirFallback := filepath.Join(homedir, ".dotfiles", "home")
+
+	candidate, err := filepath.Abs(
+		resolveSetting(flags.DotfilesFilesDir, "DOTS_DOTFILES_FILES_DIR", "", dotfilesFilesDirFallback).Value,
+	)
+	if err != nil {
+		return Settings{}, err
+	}
+
+	settings.ConfigFile = FindConfigFile(filepath.Dir(candidate), ResolveConfigDir(homedir))
+
+	if settings.ConfigFile != "" {
+		config, err := ReadConfig(settings.ConfigFile)
+		if err != nil {
+			return Settings{}, errors.Join(
+				fmt.Errorf("error reading config %s", settings.ConfigFile),
+				err,
+			)
+		}
+
+		settings.Config = config
+	}
+
+	configDir := filepath.Dir(settings.ConfigFile)
+
+	settings.DotfilesFilesDir = resolveSetting(
+		flags.DotfilesFilesDir,
+		"DOTS_DOTFILES_FILES_DIR",
+		resolveConfigPath(settings.Config.FilesDir, configDir, homedir),
+		dotfilesFilesDirFallback,
+	)
+	settings.TargetDir = resolveSetting(
+		flags.TargetDir,
+		"DOTS_TARGET_DIR",
+		resolveConfigPath(settings.Config.TargetDir, configDir, homedir),
+		homedir,
+	)
+	settings.Mode = resolveSetting(
+		flags.Mode,
+		"DOTS_MODE",
+		settings.Config.Mode,
+		string(DeployModeCopy),
+	)
+	settings.Profiles = resolveSetting(
+		flags.Profile,
+		"DOTS_PROFILE",
+		strings.Join(settings.Config.Profiles, ","),
+		"",
+	)
+	settings.Identity = resolveSetting(
+		flags.Identity,
+		"DOTS_IDENTITY",
+		resolveConfigPath(settings.Config.Identity, configDir, homedir),
+		ResolveIdentityFile(homedir),
+	)
+	settings.MergeTool = resolveSetting(nil, "DOTS_MERGETOOL", settings.Config.MergeTool, "")
+
+	return settings, nil
+}
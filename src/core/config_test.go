@@ -0,0 +1,253 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadConfig()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should read a toml config", func() {
+		path := filepath.Join(workingDir, "dots.toml")
+
+		os.WriteFile(path, []byte(`
+filesDir = "home"
+mode = "link"
+ignore = ["*.swp"]
+profiles = ["work"]
+unmanagedRoots = [".config"]
+
+[[hooks.postApply]]
+command = "echo foo"
+path = ".config"
+`), 0o600)
+
+		config, err := core.ReadConfig(path)
+
+		Expect(err).To(BeNil())
+		Expect(config).To(Equal(core.Config{
+			FilesDir:       "home",
+			Mode:           "link",
+			Ignore:         []string{"*.swp"},
+			Profiles:       []string{"work"},
+			UnmanagedRoots: []string{".config"},
+			Hooks: core.ConfigHooks{
+				PostApply: []core.ConfigHook{{Command: "echo foo", Path: ".config"}},
+			},
+		}))
+	})
+
+	It("should read a yaml config", func() {
+		path := filepath.Join(workingDir, "dots.yaml")
+
+		os.WriteFile(path, []byte("targetDir: /foo\nignore:\n  - .DS_Store\n"), 0o600)
+
+		config, err := core.ReadConfig(path)
+
+		Expect(err).To(BeNil())
+		Expect(config).To(Equal(core.Config{TargetDir: "/foo", Ignore: []string{".DS_Store"}}))
+	})
+
+	It("should read an empty yaml config", func() {
+		path := filepath.Join(workingDir, "dots.yaml")
+
+		os.WriteFile(path, []byte(""), 0o600)
+
+		config, err := core.ReadConfig(path)
+
+		Expect(err).To(BeNil())
+		Expect(config).To(Equal(core.Config{}))
+	})
+
+	It("should return an error for unknown toml keys", func() {
+		path := filepath.Join(workingDir, "dots.toml")
+
+		os.WriteFile(path, []byte("foo = \"bar\"\n"), 0o600)
+
+		_, err := core.ReadConfig(path)
+
+		Expect(err).To(MatchError("unknown config key foo"))
+	})
+
+	It("should return an error for unknown yaml keys", func() {
+		path := filepath.Join(workingDir, "dots.yaml")
+
+		os.WriteFile(path, []byte("foo: bar\n"), 0o600)
+
+		_, err := core.ReadConfig(path)
+
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+var _ = Describe("FindConfigFile()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return the first config file found", func() {
+		first := filepath.Join(workingDir, "first")
+		second := filepath.Join(workingDir, "second")
+
+		os.MkdirAll(first, os.ModePerm)
+		os.MkdirAll(second, os.ModePerm)
+		os.WriteFile(filepath.Join(second, "dots.yaml"), []byte(""), 0o600)
+
+		Expect(core.FindConfigFile(first, second)).To(Equal(filepath.Join(second, "dots.yaml")))
+
+		os.WriteFile(filepath.Join(first, "dots.toml"), []byte(""), 0o600)
+
+		Expect(core.FindConfigFile(first, second)).To(Equal(filepath.Join(first, "dots.toml")))
+	})
+
+	It("should return an empty string if no config file is found", func() {
+		Expect(core.FindConfigFile(workingDir)).To(Equal(""))
+	})
+})
+
+var _ = Describe("ResolveSettings()", func() {
+	var workingDir string
+	var homedir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		homedir = filepath.Join(workingDir, "home")
+
+		os.MkdirAll(filepath.Join(homedir, ".dotfiles", "home"), os.ModePerm)
+
+		for _, key := range []string{"DOTS_DOTFILES_FILES_DIR", "DOTS_TARGET_DIR", "DOTS_MODE", "DOTS_PROFILE", "DOTS_IDENTITY", "DOTS_MERGETOOL", "XDG_CONFIG_HOME"} {
+			GinkgoT().Setenv(key, "")
+			os.Unsetenv(key)
+		}
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should use defaults when nothing is provided", func() {
+		settings, err := core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(BeNil())
+		Expect(settings).To(Equal(core.Settings{
+			Homedir: homedir,
+			DotfilesFilesDir: core.Setting{
+				Value:  filepath.Join(homedir, ".dotfiles", "home"),
+				Source: core.SettingSourceDefault,
+			},
+			TargetDir: core.Setting{Value: homedir, Source: core.SettingSourceDefault},
+			Mode:      core.Setting{Value: "copy", Source: core.SettingSourceDefault},
+			Profiles:  core.Setting{Value: "", Source: core.SettingSourceDefault},
+			Identity: core.Setting{
+				Value:  filepath.Join(homedir, ".config", "dots", "key.txt"),
+				Source: core.SettingSourceDefault,
+			},
+			MergeTool: core.Setting{Value: "", Source: core.SettingSourceDefault},
+		}))
+	})
+
+	It("should read the config from the dotfiles repo root", func() {
+		configFile := filepath.Join(homedir, ".dotfiles", "dots.toml")
+
+		os.WriteFile(
+			configFile,
+			[]byte("filesDir = \"files\"\ntargetDir = \"~/target\"\nmode = \"link\"\nprofiles = [\"work\", \"dev\"]\nidentity = \"key.txt\"\n"),
+			0o600,
+		)
+
+		settings, err := core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(BeNil())
+		Expect(settings.ConfigFile).To(Equal(configFile))
+		Expect(settings.DotfilesFilesDir).To(Equal(core.Setting{
+			Value:  filepath.Join(homedir, ".dotfiles", "files"),
+			Source: core.SettingSourceConfig,
+		}))
+		Expect(settings.TargetDir).To(Equal(core.Setting{
+			Value:  filepath.Join(homedir, "target"),
+			Source: core.SettingSourceConfig,
+		}))
+		Expect(settings.Mode).To(Equal(core.Setting{Value: "link", Source: core.SettingSourceConfig}))
+		Expect(
+			settings.Profiles,
+		).To(Equal(core.Setting{Value: "work,dev", Source: core.SettingSourceConfig}))
+		Expect(settings.Identity).To(Equal(core.Setting{
+			Value:  filepath.Join(homedir, ".dotfiles", "key.txt"),
+			Source: core.SettingSourceConfig,
+		}))
+	})
+
+	It("should read the config from `XDG_CONFIG_HOME`", func() {
+		configDir := filepath.Join(workingDir, "config")
+		configFile := filepath.Join(configDir, "dots", "dots.yaml")
+
+		GinkgoT().Setenv("XDG_CONFIG_HOME", configDir)
+		os.MkdirAll(filepath.Join(configDir, "dots"), os.ModePerm)
+		os.WriteFile(configFile, []byte("mode: link\n"), 0o600)
+
+		settings, err := core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(BeNil())
+		Expect(settings.ConfigFile).To(Equal(configFile))
+		Expect(settings.Mode).To(Equal(core.Setting{Value: "link", Source: core.SettingSourceConfig}))
+	})
+
+	It("should prefer env over config and flags over env", func() {
+		os.WriteFile(filepath.Join(homedir, ".dotfiles", "dots.toml"), []byte("mode = \"link\"\ntargetDir = \"/config\"\n"), 0o600)
+		GinkgoT().Setenv("DOTS_MODE", "foo")
+		GinkgoT().Setenv("DOTS_TARGET_DIR", "/env")
+
+		flagMode := "bar"
+
+		settings, err := core.ResolveSettings(homedir, core.SettingsFlags{Mode: &flagMode})
+
+		Expect(err).To(BeNil())
+		Expect(settings.Mode).To(Equal(core.Setting{Value: "bar", Source: core.SettingSourceFlag}))
+		Expect(settings.TargetDir).To(Equal(core.Setting{Value: "/env", Source: core.SettingSourceEnv}))
+	})
+
+	It("should read the merge tool from env or config", func() {
+		os.WriteFile(filepath.Join(homedir, ".dotfiles", "dots.toml"), []byte("mergeTool = \"meld\"\n"), 0o600)
+
+		settings, err := core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(BeNil())
+		Expect(settings.MergeTool).To(Equal(core.Setting{Value: "meld", Source: core.SettingSourceConfig}))
+
+		GinkgoT().Setenv("DOTS_MERGETOOL", "vimdiff")
+
+		settings, err = core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(BeNil())
+		Expect(settings.MergeTool).To(Equal(core.Setting{Value: "vimdiff", Source: core.SettingSourceEnv}))
+	})
+
+	It("should return an error if the config is not valid", func() {
+		configFile := filepath.Join(homedir, ".dotfiles", "dots.toml")
+
+		os.WriteFile(configFile, []byte("foo = \"bar\"\n"), 0o600)
+
+		_, err := core.ResolveSettings(homedir, core.SettingsFlags{})
+
+		Expect(err).To(MatchError("error reading config " + configFile + "\nunknown config key foo"))
+	})
+})
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func ResolveRepoDir(dotfilesFilesDir string) string {
+	return filepath.Dir(dotfilesFilesDir)
+}
+
+func RunGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+
+	return cmd.CombinedOutput()
+}
+
+func ExecGit(dir string, args ...string) error {
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func gitError(output []byte, err error) error {
+	if message := strings.TrimSpace(string(output)); message != "" {
+		return errors.Join(errors.New(message), err)
+	}
+
+	return err
+}
+
+func IsGitClean(dir string) (bool, error) {
+	output, err := RunGit(dir, "status", "--porcelain")
+	if err != nil {
+		return false, gitError(output, err)
+	}
+
+	return strings.TrimSpace(string(output)) == "", nil
+}
+
+func HasStagedChanges(dir string) (bool, error) {
+	output, err := RunGit(dir, "diff", "--cached", "--quiet")
+	if err == nil {
+		return false, nil
+	}
+
+	var exitErr *exec.ExitError
+
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return true, nil
+	}
+
+	return false, gitError(output, err)
+}
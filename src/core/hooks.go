@@ -0,0 +1,134 @@
+package core
+
+import (
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+type HookStage string
+
+const (
+	HookStagePreApply  HookStage = "pre-apply"
+	HookStagePostApply HookStage = "post-apply"
+	HookStagePreAdopt  HookStage = "pre-adopt"
+	HookStagePostAdopt HookStage = "post-adopt"
+)
+
+func ResolveHooksDir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".dots", "hooks")
+}
+
+func quoteShellArg(arg string) string {
+	return "'" + strings.ReplaceAll(arg, "'", `'\''`) + "'"
+}
+
+func FindHooks(hooksDir string, stage HookStage) ([]ConfigHook, error) {
+	var hooks []ConfigHook
+
+	root := filepath.Join(hooksDir, string(stage))
+
+	if _, err := os.Stat(root); err != nil {
+		return nil, nil
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+
+		if info.Mode().Perm()&0o111 == 0 {
+			return nil
+		}
+
+		scope, err := filepath.Rel(root, filepath.Dir(path))
+		if err != nil {
+			return err
+		}
+
+		if scope == "." {
+			scope = ""
+		}
+
+		hooks = append(hooks, ConfigHook{Command: quoteShellArg(path), Path: scope})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return hooks, nil
+}
+
+func ResolveHooks(config ConfigHooks, hooksDir string) (ConfigHooks, error) {
+	hooks := config
+	stages := []struct {
+		stage HookStage
+		hooks *[]ConfigHook
+	}{
+		{HookStagePreApply, &hooks.PreApply},
+		{HookStagePostApply, &hooks.PostApply},
+		{HookStagePreAdopt, &hooks.PreAdopt},
+		{HookStagePostAdopt, &hooks.PostAdopt},
+	}
+
+	for _, stage := range stages {
+		found, err := FindHooks(hooksDir, stage.stage)
+		if err != nil {
+			return ConfigHooks{}, err
+		}
+
+		*stage.hooks = append(slices.Clone(*stage.hooks), found...)
+	}
+
+	return hooks, nil
+}
+
+func (h ConfigHook) Matches(changed []string) bool {
+	if h.Path == "" {
+		return true
+	}
+
+	scope := filepath.Clean(h.Path)
+
+	for _, path := range changed {
+		if path == scope || strings.HasPrefix(path, scope+string(filepath.Separator)) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func RunHook(hook ConfigHook, dir string, env []string) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", hook.Command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+
+	return cmd.CombinedOutput()
+}
+
+func ExecHook(hook ConfigHook, dir string, env []string, stdout io.Writer) error {
+	cmd := exec.Command("sh", "-c", hook.Command)
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), env...)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
@@ -0,0 +1,124 @@
+package core_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ResolveHooks()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should append executable hooks from the hooks dir scoped by their subdirectory", func() {
+		hooksDir := core.ResolveHooksDir(workingDir)
+
+		os.MkdirAll(filepath.Join(hooksDir, "post-apply", ".config", "systemd"), os.ModePerm)
+		os.WriteFile(filepath.Join(hooksDir, "post-apply", "1"), []byte("#!/bin/sh\n"), 0o700)
+		os.WriteFile(filepath.Join(hooksDir, "post-apply", "2"), []byte("#!/bin/sh\n"), 0o600)
+		os.WriteFile(filepath.Join(hooksDir, "post-apply", ".config", "systemd", "3"), []byte("#!/bin/sh\n"), 0o700)
+
+		hooks, err := core.ResolveHooks(core.ConfigHooks{
+			PostApply: []core.ConfigHook{{Command: "echo foo", Path: ".config"}},
+		}, hooksDir)
+
+		Expect(err).To(BeNil())
+		Expect(hooks).To(Equal(core.ConfigHooks{
+			PostApply: []core.ConfigHook{
+				{Command: "echo foo", Path: ".config"},
+				{Command: "'" + filepath.Join(hooksDir, "post-apply", ".config", "systemd", "3") + "'", Path: filepath.Join(".config", "systemd")},
+				{Command: "'" + filepath.Join(hooksDir, "post-apply", "1") + "'"},
+			},
+		}))
+	})
+
+	It("should return the config hooks if the hooks dir does not exists", func() {
+		hooks, err := core.ResolveHooks(core.ConfigHooks{
+			PreAdopt: []core.ConfigHook{{Command: "echo foo"}},
+		}, core.ResolveHooksDir(workingDir))
+
+		Expect(err).To(BeNil())
+		Expect(hooks).To(Equal(core.ConfigHooks{
+			PreAdopt: []core.ConfigHook{{Command: "echo foo"}},
+		}))
+	})
+})
+
+var _ = Describe("ConfigHook.Matches()", func() {
+	It("should match unscoped hooks", func() {
+		Expect(core.ConfigHook{}.Matches(nil)).To(BeTrue())
+	})
+
+	It("should match changed paths under the hook path", func() {
+		hook := core.ConfigHook{Path: ".config/systemd/user/"}
+
+		Expect(hook.Matches([]string{".config/systemd/user/foo.service"})).To(BeTrue())
+		Expect(hook.Matches([]string{".config/systemd/user"})).To(BeTrue())
+		Expect(hook.Matches([]string{".config/systemd/userfoo"})).To(BeFalse())
+		Expect(hook.Matches([]string{".bashrc"})).To(BeFalse())
+	})
+})
+
+var _ = Describe("RunHook()", func() {
+	It("should run the command with the given env and dir", func() {
+		dir := os.TempDir()
+
+		output, err := core.RunHook(core.ConfigHook{Command: "echo $FOO; pwd"}, dir, []string{"FOO=bar"})
+
+		Expect(err).To(BeNil())
+		Expect(string(output)).To(Equal("bar\n" + dir + "\n"))
+	})
+
+	It("should return an error if the command fails", func() {
+		output, err := core.RunHook(core.ConfigHook{Command: "echo foo; exit 3"}, os.TempDir(), nil)
+
+		Expect(err).To(MatchError("exit status 3"))
+		Expect(string(output)).To(Equal("foo\n"))
+	})
+})
+
+var _ = Describe("ExecHook()", func() {
+	It("should run the command with stdin attached and write to the given stdout", func() {
+		dir := os.TempDir()
+		stdin := os.Stdin
+		reader, writer, _ := os.Pipe()
+
+		defer func() { os.Stdin = stdin }()
+
+		os.Stdin = reader
+
+		writer.Write([]byte("baz\n"))
+		writer.Close()
+
+		var stdout bytes.Buffer
+
+		err := core.ExecHook(
+			core.ConfigHook{Command: "read line; echo $FOO $line; pwd"},
+			dir,
+			[]string{"FOO=bar"},
+			&stdout,
+		)
+
+		Expect(err).To(BeNil())
+		Expect(stdout.String()).To(Equal("bar baz\n" + dir + "\n"))
+	})
+
+	It("should return an error if the command fails", func() {
+		var stdout bytes.Buffer
+
+		err := core.ExecHook(core.ConfigHook{Command: "exit 3"}, os.TempDir(), nil, &stdout)
+
+		Expect(err).To(MatchError("exit status 3"))
+	})
+})
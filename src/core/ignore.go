@@ -0,0 +1,267 @@
+package core
+
+import (
+	"bufio"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const IgnoreFileName = ".dotsignore"
+
+type IgnoreRule struct {
+	Source   string
+	Line     int
+	Pattern  string
+	Negate   bool
+	DirOnly  bool
+	base     string
+	compiled *regexp.Regexp
+}
+
+type IgnoreMatcher struct {
+	Rules []IgnoreRule
+}
+
+func compileIgnoreGlob(glob string, anchored bool) (*regexp.Regexp, error) {
+	var builder strings.Builder
+
+	builder.WriteString("^")
+
+	if !anchored {
+		builder.WriteString("(?:.*/)?")
+	}
+
+	for i := 0; i < len(glob); i++ {
+		char := glob[i]
+
+		switch {
+		case char == '*' && i+1 < len(glob) && glob[i+1] == '*':
+			end := i
+
+			for end < len(glob) && glob[end] == '*' {
+				end += 1
+			}
+
+			atStart := i == 0 || glob[i-1] == '/'
+
+			switch {
+			case atStart && end < len(glob) && glob[end] == '/':
+				builder.WriteString("(?:.*/)?")
+				i = end
+			case atStart && end == len(glob):
+				builder.WriteString(".*")
+				i = end - 1
+			default:
+				builder.WriteString("[^/]*")
+				i = end - 1
+			}
+		case char == '*':
+			builder.WriteString("[^/]*")
+		case char == '?':
+			builder.WriteString("[^/]")
+		case char == '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+
+			if end < 0 {
+				builder.WriteString(regexp.QuoteMeta(string(char)))
+
+				continue
+			}
+
+			class := glob[i+1 : i+1+end]
+
+			if strings.HasPrefix(class, "!") {
+				class = "^" + class[1:]
+			}
+
+			builder.WriteString("[" + class + "]")
+			i += end + 1
+		case char == '\\' && i+1 < len(glob):
+			builder.WriteString(regexp.QuoteMeta(string(glob[i+1])))
+			i += 1
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(char)))
+		}
+	}
+
+	builder.WriteString("$")
+
+	return regexp.Compile(builder.String())
+}
+
+func ParseIgnoreRule(line string, source string, lineNumber int, base string) (IgnoreRule, bool, error) {
+	pattern := strings.TrimRight(line, " \t\r")
+
+	if pattern == "" || strings.HasPrefix(pattern, "#") {
+		return IgnoreRule{}, false, nil
+	}
+
+	rule := IgnoreRule{Source: source, Line: lineNumber, Pattern: pattern, base: base}
+	glob := pattern
+
+	if strings.HasPrefix(glob, "!") {
+		rule.Negate = true
+		glob = glob[1:]
+	} else if strings.HasPrefix(glob, "\\!") || strings.HasPrefix(glob, "\\#") {
+		glob = glob[1:]
+	}
+
+	if strings.HasSuffix(glob, "/") {
+		rule.DirOnly = true
+		glob = strings.TrimRight(glob, "/")
+	}
+
+	if glob == "" {
+		return IgnoreRule{}, false, nil
+	}
+
+	anchored := strings.Contains(glob, "/")
+	glob = strings.TrimPrefix(glob, "/")
+
+	compiled, err := compileIgnoreGlob(glob, anchored)
+	if err != nil {
+		return IgnoreRule{}, false, err
+	}
+
+	rule.compiled = compiled
+
+	return rule, true, nil
+}
+
+func (r IgnoreRule) Matches(relative string, isDir bool) bool {
+	if r.DirOnly && !isDir {
+		return false
+	}
+
+	if r.base != "" {
+		if !strings.HasPrefix(relative, r.base+"/") {
+			return false
+		}
+
+		relative = strings.TrimPrefix(relative, r.base+"/")
+	}
+
+	return r.compiled.MatchString(relative)
+}
+
+func readIgnoreFile(root string, path string) ([]IgnoreRule, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	base := filepath.ToSlash(strings.TrimPrefix(filepath.Dir(path), root))
+	base = strings.Trim(base, "/")
+
+	var rules []IgnoreRule
+
+	scanner := bufio.NewScanner(file)
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber += 1
+
+		rule, ok, err := ParseIgnoreRule(scanner.Text(), path, lineNumber, base)
+		if err != nil {
+			return nil, err
+		}
+
+		if ok {
+			rules = append(rules, rule)
+		}
+	}
+
+	return rules, scanner.Err()
+}
+
+func ignoreRuleDepth(rule IgnoreRule) int {
+	if rule.base == "" {
+		return 0
+	}
+
+	return strings.Count(rule.base, "/") + 1
+}
+
+func LoadIgnoreMatcher(root string, patterns []string) (IgnoreMatcher, error) {
+	defaultRule, _, _ := ParseIgnoreRule(IgnoreFileName, "(default)", 0, "")
+	matcher := IgnoreMatcher{Rules: []IgnoreRule{defaultRule}}
+
+	for i, pattern := range patterns {
+		rule, ok, err := ParseIgnoreRule(pattern, "(config)", i+1, "")
+		if err != nil {
+			return IgnoreMatcher{}, err
+		}
+
+		if ok {
+			matcher.Rules = append(matcher.Rules, rule)
+		}
+	}
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+
+		if d.Name() != IgnoreFileName || !d.Type().IsRegular() {
+			return nil
+		}
+
+		rules, err := readIgnoreFile(root, path)
+		if err != nil {
+			return err
+		}
+
+		matcher.Rules = append(matcher.Rules, rules...)
+
+		return nil
+	})
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return IgnoreMatcher{}, err
+	}
+
+	slices.SortStableFunc(matcher.Rules, func(a IgnoreRule, b IgnoreRule) int {
+		return ignoreRuleDepth(a) - ignoreRuleDepth(b)
+	})
+
+	return matcher, nil
+}
+
+func (m IgnoreMatcher) matchSelf(relative string, isDir bool) (IgnoreRule, bool) {
+	for i := len(m.Rules) - 1; i >= 0; i-- {
+		if m.Rules[i].Matches(relative, isDir) {
+			return m.Rules[i], true
+		}
+	}
+
+	return IgnoreRule{}, false
+}
+
+func (m IgnoreMatcher) Match(relative string, isDir bool) (IgnoreRule, bool) {
+	relative = strings.Trim(filepath.ToSlash(relative), "/")
+
+	if relative == "" || relative == "." {
+		return IgnoreRule{}, false
+	}
+
+	parts := strings.Split(relative, "/")
+
+	for i := 1; i < len(parts); i++ {
+		if rule, ok := m.matchSelf(strings.Join(parts[:i], "/"), true); ok && !rule.Negate {
+			return rule, true
+		}
+	}
+
+	return m.matchSelf(relative, isDir)
+}
+
+func (m IgnoreMatcher) IsIgnored(relative string, isDir bool) bool {
+	rule, ok := m.Match(relative, isDir)
+
+	return ok && !rule.Negate
+}
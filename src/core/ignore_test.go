@@ -0,0 +1,141 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseIgnoreRule()", func() {
+	It("should skip blank lines and comments", func() {
+		_, ok, err := core.ParseIgnoreRule("   ", "foo", 1, "")
+
+		Expect(ok).To(BeFalse())
+		Expect(err).To(BeNil())
+
+		_, ok, err = core.ParseIgnoreRule("# comment", "foo", 2, "")
+
+		Expect(ok).To(BeFalse())
+		Expect(err).To(BeNil())
+	})
+
+	It("should parse negated and directory only rules", func() {
+		rule, ok, err := core.ParseIgnoreRule("!cache/", "foo", 3, "")
+
+		Expect(ok).To(BeTrue())
+		Expect(err).To(BeNil())
+		Expect(rule.Source).To(Equal("foo"))
+		Expect(rule.Line).To(Equal(3))
+		Expect(rule.Pattern).To(Equal("!cache/"))
+		Expect(rule.Negate).To(BeTrue())
+		Expect(rule.DirOnly).To(BeTrue())
+	})
+})
+
+var _ = Describe("IgnoreRule.Matches()", func() {
+	DescribeTable(
+		"should match paths like gitignore",
+		func(pattern string, base string, path string, isDir bool, expected bool) {
+			rule, _, _ := core.ParseIgnoreRule(pattern, "foo", 1, base)
+
+			Expect(rule.Matches(path, isDir)).To(Equal(expected))
+		},
+		Entry("basename at any depth", "*.swp", "", ".config/nvim/init.lua.swp", false, true),
+		Entry("basename not matching", "*.swp", "", ".config/nvim/init.lua", false, false),
+		Entry("anchored with leading slash", "/.DS_Store", "", ".config/.DS_Store", false, false),
+		Entry("anchored at the root", "/.DS_Store", "", ".DS_Store", false, true),
+		Entry("anchored with a middle slash", ".config/gh/hosts.yml", "", ".config/gh/hosts.yml", false, true),
+		Entry("leading double star", "**/cache", "", "a/b/cache", true, true),
+		Entry("trailing double star", ".cache/**", "", ".cache/a/b", false, true),
+		Entry("trailing double star does not match the dir", ".cache/**", "", ".cache", true, false),
+		Entry("middle double star", "a/**/b", "", "a/x/y/b", false, true),
+		Entry("middle double star with no dirs", "a/**/b", "", "a/b", false, true),
+		Entry("single star does not cross dirs", "a/*", "", "a/b/c", false, false),
+		Entry("question mark", "fo?", "", "foo", false, true),
+		Entry("character class", "[ab].txt", "", "b.txt", false, true),
+		Entry("negated character class", "[!ab].txt", "", "b.txt", false, false),
+		Entry("directory only on file", "cache/", "", "cache", false, false),
+		Entry("directory only on dir", "cache/", "", "cache", true, true),
+		Entry("relative to the ignore file dir", "foo", ".config", ".config/foo", false, true),
+		Entry("outside of the ignore file dir", "foo", ".config", "foo", false, false),
+	)
+})
+
+var _ = Describe("LoadIgnoreMatcher()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should always ignore ignore files", func() {
+		matcher, err := core.LoadIgnoreMatcher(workingDir, nil)
+
+		Expect(err).To(BeNil())
+		Expect(matcher.IsIgnored(".dotsignore", false)).To(BeTrue())
+		Expect(matcher.IsIgnored(".config/.dotsignore", false)).To(BeTrue())
+		Expect(matcher.IsIgnored(".bashrc", false)).To(BeFalse())
+	})
+
+	It("should not fail if the root does not exist", func() {
+		matcher, err := core.LoadIgnoreMatcher(filepath.Join(workingDir, "foo"), nil)
+
+		Expect(err).To(BeNil())
+		Expect(matcher.IsIgnored(".bashrc", false)).To(BeFalse())
+	})
+
+	It("should load nested ignore files with negation", func() {
+		os.MkdirAll(filepath.Join(workingDir, ".config", "gh"), os.ModePerm)
+		os.WriteFile(filepath.Join(workingDir, ".dotsignore"), []byte("# comment\n*.swp\n*.log\n"), 0o600)
+		os.WriteFile(filepath.Join(workingDir, ".config", ".dotsignore"), []byte("gh/hosts.yml\n!keep.log\n"), 0o600)
+
+		matcher, err := core.LoadIgnoreMatcher(workingDir, nil)
+
+		Expect(err).To(BeNil())
+		Expect(matcher.IsIgnored("foo.swp", false)).To(BeTrue())
+		Expect(matcher.IsIgnored("keep.log", false)).To(BeTrue())
+		Expect(matcher.IsIgnored(".config/keep.log", false)).To(BeFalse())
+		Expect(matcher.IsIgnored(".config/gh/hosts.yml", false)).To(BeTrue())
+		Expect(matcher.IsIgnored("gh/hosts.yml", false)).To(BeFalse())
+
+		rule, ok := matcher.Match(".config/gh/hosts.yml", false)
+
+		Expect(ok).To(BeTrue())
+		Expect(rule.Source).To(Equal(filepath.Join(workingDir, ".config", ".dotsignore")))
+		Expect(rule.Line).To(Equal(1))
+		Expect(rule.Pattern).To(Equal("gh/hosts.yml"))
+	})
+
+	It("should ignore everything under an ignored directory", func() {
+		os.WriteFile(filepath.Join(workingDir, ".dotsignore"), []byte("cache/\n!cache/keep\n"), 0o600)
+
+		matcher, err := core.LoadIgnoreMatcher(workingDir, nil)
+
+		Expect(err).To(BeNil())
+		Expect(matcher.IsIgnored("cache/keep", false)).To(BeTrue())
+		Expect(matcher.IsIgnored("a/cache/foo", false)).To(BeTrue())
+	})
+
+	It("should load extra patterns before ignore files", func() {
+		os.WriteFile(filepath.Join(workingDir, ".dotsignore"), []byte("!keep.log\n"), 0o600)
+
+		matcher, err := core.LoadIgnoreMatcher(workingDir, []string{"*.log"})
+
+		Expect(err).To(BeNil())
+		Expect(matcher.IsIgnored("foo.log", false)).To(BeTrue())
+		Expect(matcher.IsIgnored("keep.log", false)).To(BeFalse())
+
+		rule, ok := matcher.Match("foo.log", false)
+
+		Expect(ok).To(BeTrue())
+		Expect(rule.Source).To(Equal("(config)"))
+		Expect(rule.Line).To(Equal(1))
+	})
+})
@@ -0,0 +1,107 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gookit/goutil/fsutil"
+)
+
+type Layer struct {
+	Name string
+	Dir  string
+}
+
+func ParseProfiles(profiles string) []string {
+	var result []string
+
+	for profile := range strings.SplitSeq(profiles, ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			result = append(result, profile)
+		}
+	}
+
+	return result
+}
+
+func ResolveLayers(
+	dotfilesFilesDir string,
+	profiles []string,
+	hostname string,
+	goos string,
+) ([]Layer, error) {
+	repoRoot := filepath.Dir(dotfilesFilesDir)
+	layers := []Layer{{Name: "base", Dir: dotfilesFilesDir}}
+
+	for _, auto := range [][2]string{{"os", goos}, {"hosts", hostname}} {
+		if auto[1] == "" {
+			continue
+		}
+
+		name := filepath.Join(auto[0], auto[1])
+		dir := filepath.Join(repoRoot, name)
+
+		if fsutil.IsDir(dir) && IsPathReadable(dir) {
+			layers = append(layers, Layer{Name: filepath.ToSlash(name), Dir: dir})
+		}
+	}
+
+	for _, profile := range profiles {
+		name := filepath.Join("profiles", profile)
+		dir := filepath.Join(repoRoot, name)
+
+		if !fsutil.IsDir(dir) || !IsPathReadable(dir) {
+			return nil, fmt.Errorf(
+				"profile %s does not exists or is not readable, expected directory %s",
+				profile,
+				dir,
+			)
+		}
+
+		layers = append(layers, Layer{Name: filepath.ToSlash(name), Dir: dir})
+	}
+
+	return layers, nil
+}
+
+func FindLayer(layers []Layer, path string) (Layer, bool) {
+	var found Layer
+	ok := false
+
+	for _, layer := range layers {
+		if IsSubpath(path, layer.Dir) && len(layer.Dir) > len(found.Dir) {
+			found = layer
+			ok = true
+		}
+	}
+
+	return found, ok
+}
+
+func FindOwnerLayer(layers []Layer, relative string) (Layer, bool) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		if _, err := os.Lstat(filepath.Join(layers[i].Dir, relative)); err == nil {
+			return layers[i], true
+		}
+
+		for _, suffix := range []string{TemplateSuffix, EncryptedSuffix} {
+			if _, err := os.Lstat(filepath.Join(layers[i].Dir, relative+suffix)); err == nil {
+				return layers[i], true
+			}
+		}
+	}
+
+	return Layer{}, false
+}
+
+func IsLayerDir(layers []Layer, path string) bool {
+	for _, layer := range layers {
+		if layer.Dir == path {
+			return true
+		}
+	}
+
+	return false
+}
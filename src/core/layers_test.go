@@ -0,0 +1,132 @@
+package core_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseProfiles()", func() {
+	It("should split comma separated profiles", func() {
+		Expect(core.ParseProfiles(" work, dev ,,")).To(Equal([]string{"work", "dev"}))
+		Expect(core.ParseProfiles("")).To(BeNil())
+	})
+})
+
+var _ = Describe("ResolveLayers()", func() {
+	var workingDir string
+	var dotfilesFilesDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		dotfilesFilesDir = filepath.Join(workingDir, "home")
+
+		os.MkdirAll(dotfilesFilesDir, os.ModePerm)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should only return the base layer if no overlays exist", func() {
+		layers, err := core.ResolveLayers(dotfilesFilesDir, nil, "foo", "linux")
+
+		Expect(err).To(BeNil())
+		Expect(layers).To(Equal([]core.Layer{{Name: "base", Dir: dotfilesFilesDir}}))
+	})
+
+	It("should return os, host and profile layers in order", func() {
+		os.MkdirAll(filepath.Join(workingDir, "os", "linux"), os.ModePerm)
+		os.MkdirAll(filepath.Join(workingDir, "hosts", "foo"), os.ModePerm)
+		os.MkdirAll(filepath.Join(workingDir, "hosts", "bar"), os.ModePerm)
+		os.MkdirAll(filepath.Join(workingDir, "profiles", "work"), os.ModePerm)
+		os.MkdirAll(filepath.Join(workingDir, "profiles", "dev"), os.ModePerm)
+
+		layers, err := core.ResolveLayers(dotfilesFilesDir, []string{"work", "dev"}, "foo", "linux")
+
+		Expect(err).To(BeNil())
+		Expect(layers).To(Equal([]core.Layer{
+			{Name: "base", Dir: dotfilesFilesDir},
+			{Name: "os/linux", Dir: filepath.Join(workingDir, "os", "linux")},
+			{Name: "hosts/foo", Dir: filepath.Join(workingDir, "hosts", "foo")},
+			{Name: "profiles/work", Dir: filepath.Join(workingDir, "profiles", "work")},
+			{Name: "profiles/dev", Dir: filepath.Join(workingDir, "profiles", "dev")},
+		}))
+	})
+
+	It("should not add a host layer if the hostname is empty", func() {
+		os.MkdirAll(filepath.Join(workingDir, "hosts"), os.ModePerm)
+
+		layers, err := core.ResolveLayers(dotfilesFilesDir, nil, "", "")
+
+		Expect(err).To(BeNil())
+		Expect(layers).To(Equal([]core.Layer{{Name: "base", Dir: dotfilesFilesDir}}))
+	})
+
+	It("should return an error if a profile does not exist", func() {
+		_, err := core.ResolveLayers(dotfilesFilesDir, []string{"work"}, "foo", "linux")
+
+		Expect(err).To(MatchError(fmt.Sprintf(
+			"profile work does not exists or is not readable, expected directory %s",
+			filepath.Join(workingDir, "profiles", "work"),
+		)))
+	})
+})
+
+var _ = Describe("FindLayer()", func() {
+	It("should find the layer that contains a path", func() {
+		layers := []core.Layer{{Name: "base", Dir: "/foo/home"}, {Name: "hosts/bar", Dir: "/foo/hosts/bar"}}
+
+		layer, ok := core.FindLayer(layers, "/foo/hosts/bar/.bashrc")
+
+		Expect(ok).To(BeTrue())
+		Expect(layer).To(Equal(layers[1]))
+
+		_, ok = core.FindLayer(layers, "/foo/homes/.bashrc")
+
+		Expect(ok).To(BeFalse())
+	})
+})
+
+var _ = Describe("FindOwnerLayer()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return the last layer that has the path", func() {
+		layers := []core.Layer{
+			{Name: "base", Dir: filepath.Join(workingDir, "home")},
+			{Name: "profiles/work", Dir: filepath.Join(workingDir, "profiles", "work")},
+		}
+
+		os.MkdirAll(layers[0].Dir, os.ModePerm)
+		os.MkdirAll(layers[1].Dir, os.ModePerm)
+		os.WriteFile(filepath.Join(layers[0].Dir, "1"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(layers[0].Dir, "2"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(layers[1].Dir, "2"), []byte("foo"), 0o600)
+
+		layer, ok := core.FindOwnerLayer(layers, "1")
+
+		Expect(ok).To(BeTrue())
+		Expect(layer).To(Equal(layers[0]))
+
+		layer, ok = core.FindOwnerLayer(layers, "2")
+
+		Expect(ok).To(BeTrue())
+		Expect(layer).To(Equal(layers[1]))
+
+		_, ok = core.FindOwnerLayer(layers, "3")
+
+		Expect(ok).To(BeFalse())
+	})
+})
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/fatih/color"
 )
@@ -21,6 +23,8 @@ type ILogger interface {
 
 type logger struct {
 	ILogger
+
+	out io.Writer
 }
 
 var (
@@ -46,64 +50,68 @@ func resolveLogLevelStr(level *string) string {
 
 	if !colorMapExists {
 		colorMap = func(format string, a ...any) string {
-			return fmt.Sprintf(format, a)
+			return fmt.Sprintf(format, a...)
 		}
 	}
 
-	return colorMap(fmt.Sprintf("%s: ", *level))
+	return colorMap("%s: ", *level)
 }
 
-func log(level *string, nl bool, msg string, args ...any) {
+func log(out io.Writer, level *string, nl bool, msg string, args ...any) {
 	levelStr := resolveLogLevelStr(level)
 
-	fmt.Print(levelStr)
-	fmt.Printf(msg, args...)
+	fmt.Fprint(out, levelStr)
+	fmt.Fprintf(out, msg, args...)
 
 	if nl {
-		fmt.Println("")
+		fmt.Fprintln(out, "")
 	}
 }
 
-func (logger) Debug(msg string, args ...any) {
-	log(&logLevelDebug, false, msg, args...)
+func (l logger) Debug(msg string, args ...any) {
+	log(l.out, &logLevelDebug, false, msg, args...)
 }
 
-func (logger) Info(msg string, args ...any) {
-	log(&logLevelInfo, false, msg, args...)
+func (l logger) Info(msg string, args ...any) {
+	log(l.out, &logLevelInfo, false, msg, args...)
 }
 
-func (logger) Warn(msg string, args ...any) {
-	log(&logLevelWarn, false, msg, args...)
+func (l logger) Warn(msg string, args ...any) {
+	log(l.out, &logLevelWarn, false, msg, args...)
 }
 
-func (logger) Error(msg string, args ...any) {
-	log(&logLevelError, false, msg, args...)
+func (l logger) Error(msg string, args ...any) {
+	log(l.out, &logLevelError, false, msg, args...)
 }
 
-func (logger) Log(msg string, args ...any) {
-	log(nil, false, msg, args...)
+func (l logger) Log(msg string, args ...any) {
+	log(l.out, nil, false, msg, args...)
 }
 
-func (logger) Debugnl(msg string, args ...any) {
-	log(&logLevelDebug, true, msg, args...)
+func (l logger) Debugnl(msg string, args ...any) {
+	log(l.out, &logLevelDebug, true, msg, args...)
 }
 
-func (logger) Infonl(msg string, args ...any) {
-	log(&logLevelInfo, true, msg, args...)
+func (l logger) Infonl(msg string, args ...any) {
+	log(l.out, &logLevelInfo, true, msg, args...)
 }
 
-func (logger) Warnnl(msg string, args ...any) {
-	log(&logLevelWarn, true, msg, args...)
+func (l logger) Warnnl(msg string, args ...any) {
+	log(l.out, &logLevelWarn, true, msg, args...)
 }
 
-func (logger) Errornl(msg string, args ...any) {
-	log(&logLevelError, true, msg, args...)
+func (l logger) Errornl(msg string, args ...any) {
+	log(l.out, &logLevelError, true, msg, args...)
 }
 
-func (logger) Lognl(msg string, args ...any) {
-	log(nil, true, msg, args...)
+func (l logger) Lognl(msg string, args ...any) {
+	log(l.out, nil, true, msg, args...)
 }
 
 func MakeLogger() ILogger {
-	return logger{}
+	return logger{out: os.Stdout}
+}
+
+func MakeLoggerTo(out io.Writer) ILogger {
+	return logger{out: out}
 }
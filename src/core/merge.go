@@ -0,0 +1,211 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+func ResolveEditor() string {
+	for _, name := range []string{"VISUAL", "EDITOR"} {
+		if editor := os.Getenv(name); editor != "" {
+			return editor
+		}
+	}
+
+	return "vi"
+}
+
+func EditFile(editor string, path string) error {
+	cmd := exec.Command("sh", "-c", editor+" "+quoteShellArg(path))
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	return cmd.Run()
+}
+
+func ConflictMarkers(ours []byte, oursLabel string, theirs []byte, theirsLabel string) []byte {
+	var buffer bytes.Buffer
+
+	buffer.WriteString("<<<<<<< " + oursLabel + "\n")
+	buffer.Write(ensureTrailingNewline(ours))
+	buffer.WriteString("=======\n")
+	buffer.Write(ensureTrailingNewline(theirs))
+	buffer.WriteString(">>>>>>> " + theirsLabel + "\n")
+
+	return buffer.Bytes()
+}
+
+func HasConflictMarkers(content []byte) bool {
+	markers := [][]byte{[]byte("<<<<<<< "), []byte("======="), []byte(">>>>>>> ")}
+	next := 0
+
+	for line := range bytes.SplitSeq(content, []byte("\n")) {
+		marker := markers[next]
+
+		if (next == 1 && bytes.Equal(line, marker)) || (next != 1 && bytes.HasPrefix(line, marker)) {
+			next++
+		}
+
+		if next >= len(markers) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func ensureTrailingNewline(content []byte) []byte {
+	if len(content) <= 0 || content[len(content)-1] == '\n' {
+		return content
+	}
+
+	return append(content[:len(content):len(content)], '\n')
+}
+
+func splitLines(content []byte) []string {
+	var lines []string
+
+	for len(content) > 0 {
+		index := bytes.IndexByte(content, '\n')
+		if index < 0 {
+			lines = append(lines, string(content))
+
+			break
+		}
+
+		lines = append(lines, string(content[:index+1]))
+		content = content[index+1:]
+	}
+
+	return lines
+}
+
+func matchLines(a []string, b []string) []int {
+	lengths := make([][]int, len(a)+1)
+
+	for i := range lengths {
+		lengths[i] = make([]int, len(b)+1)
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lengths[i][j] = lengths[i+1][j+1] + 1
+			} else {
+				lengths[i][j] = max(lengths[i+1][j], lengths[i][j+1])
+			}
+		}
+	}
+
+	matches := make([]int, len(a))
+
+	for i := range matches {
+		matches[i] = -1
+	}
+
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			matches[i] = j
+			i += 1
+			j += 1
+		case lengths[i+1][j] >= lengths[i][j+1]:
+			i += 1
+		default:
+			j += 1
+		}
+	}
+
+	return matches
+}
+
+func Merge3(
+	base []byte,
+	ours []byte,
+	oursLabel string,
+	theirs []byte,
+	theirsLabel string,
+) ([]byte, bool) {
+	baseLines := splitLines(base)
+	oursLines := splitLines(ours)
+	theirsLines := splitLines(theirs)
+	oursMatches := matchLines(baseLines, oursLines)
+	theirsMatches := matchLines(baseLines, theirsLines)
+
+	var buffer bytes.Buffer
+
+	conflict := false
+	i, j, k := 0, 0, 0
+
+	mergeChunk := func(baseEnd int, oursEnd int, theirsEnd int) {
+		baseChunk := strings.Join(baseLines[i:baseEnd], "")
+		oursChunk := strings.Join(oursLines[j:oursEnd], "")
+		theirsChunk := strings.Join(theirsLines[k:theirsEnd], "")
+
+		switch {
+		case oursChunk == baseChunk:
+			buffer.WriteString(theirsChunk)
+		case theirsChunk == baseChunk || oursChunk == theirsChunk:
+			buffer.WriteString(oursChunk)
+		default:
+			conflict = true
+
+			buffer.Write(ConflictMarkers([]byte(oursChunk), oursLabel, []byte(theirsChunk), theirsLabel))
+		}
+	}
+
+	for index := range baseLines {
+		if oursMatches[index] < 0 || theirsMatches[index] < 0 {
+			continue
+		}
+
+		mergeChunk(index, oursMatches[index], theirsMatches[index])
+
+		buffer.WriteString(baseLines[index])
+
+		i, j, k = index+1, oursMatches[index]+1, theirsMatches[index]+1
+	}
+
+	mergeChunk(len(baseLines), len(oursLines), len(theirsLines))
+
+	return buffer.Bytes(), conflict
+}
+
+func RunMergeTool(tool string, dir string, base []byte, local []byte, remote []byte, merged []byte) ([]byte, error) {
+	tmp, err := os.MkdirTemp("", "dots-merge-*")
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(tmp)
+
+	files := map[string][]byte{"BASE": base, "LOCAL": local, "REMOTE": remote, "MERGED": merged}
+	env := os.Environ()
+
+	for _, name := range []string{"BASE", "LOCAL", "REMOTE", "MERGED"} {
+		path := filepath.Join(tmp, name)
+
+		if err := os.WriteFile(path, files[name], 0o600); err != nil {
+			return nil, err
+		}
+
+		env = append(env, name+"="+path)
+	}
+
+	cmd := exec.Command("sh", "-c", tool)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, err
+	}
+
+	return os.ReadFile(filepath.Join(tmp, "MERGED"))
+}
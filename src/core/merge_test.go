@@ -0,0 +1,48 @@
+package core_test
+
+import (
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ConflictMarkers()", func() {
+	It("should wrap both versions in conflict markers", func() {
+		Expect(string(core.ConflictMarkers([]byte("foo"), "ours", []byte("bar\n"), "theirs"))).To(Equal(
+			"<<<<<<< ours\nfoo\n=======\nbar\n>>>>>>> theirs\n",
+		))
+	})
+})
+
+var _ = Describe("HasConflictMarkers()", func() {
+	It("should detect conflict markers", func() {
+		Expect(core.HasConflictMarkers(core.ConflictMarkers([]byte("foo"), "a", []byte("bar"), "b"))).To(BeTrue())
+		Expect(core.HasConflictMarkers([]byte("foo\n==\n<<<<<<<<\n"))).To(BeFalse())
+	})
+
+	It("should not detect a lone separator line", func() {
+		Expect(core.HasConflictMarkers([]byte("Title\n=======\nfoo\n"))).To(BeFalse())
+	})
+
+	It("should only detect markers in order", func() {
+		Expect(core.HasConflictMarkers([]byte(">>>>>>> b\n=======\n<<<<<<< a\n"))).To(BeFalse())
+		Expect(core.HasConflictMarkers([]byte("<<<<<<< a\nfoo\n>>>>>>> b\n"))).To(BeFalse())
+	})
+})
+
+var _ = Describe("ResolveEditor()", func() {
+	It("should prefer VISUAL over EDITOR and default to vi", func() {
+		GinkgoT().Setenv("VISUAL", "")
+		GinkgoT().Setenv("EDITOR", "")
+
+		Expect(core.ResolveEditor()).To(Equal("vi"))
+
+		GinkgoT().Setenv("EDITOR", "nano")
+
+		Expect(core.ResolveEditor()).To(Equal("nano"))
+
+		GinkgoT().Setenv("VISUAL", "code --wait")
+
+		Expect(core.ResolveEditor()).To(Equal("code --wait"))
+	})
+})
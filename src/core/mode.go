@@ -0,0 +1,23 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+)
+
+type DeployMode string
+
+const (
+	DeployModeCopy DeployMode = "copy"
+	DeployModeLink DeployMode = "link"
+)
+
+var deployModes = []DeployMode{DeployModeCopy, DeployModeLink}
+
+func ParseDeployMode(mode string) (DeployMode, error) {
+	if !slices.Contains(deployModes, DeployMode(mode)) {
+		return "", fmt.Errorf("mode %s is not valid, it must be one of %v", mode, deployModes)
+	}
+
+	return DeployMode(mode), nil
+}
@@ -0,0 +1,20 @@
+package core_test
+
+import (
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseDeployMode()", func() {
+	It("should parse valid modes", func() {
+		Expect(core.ParseDeployMode("copy")).To(Equal(core.DeployModeCopy))
+		Expect(core.ParseDeployMode("link")).To(Equal(core.DeployModeLink))
+	})
+
+	It("should return an error for invalid modes", func() {
+		_, err := core.ParseDeployMode("foo")
+
+		Expect(err).To(MatchError("mode foo is not valid, it must be one of [copy link]"))
+	})
+})
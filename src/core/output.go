@@ -0,0 +1,109 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+type OutputFormat string
+
+const (
+	OutputFormatText   OutputFormat = "text"
+	OutputFormatJSON   OutputFormat = "json"
+	OutputFormatNDJSON OutputFormat = "ndjson"
+)
+
+var outputFormats = []OutputFormat{OutputFormatText, OutputFormatJSON, OutputFormatNDJSON}
+
+func ParseOutputFormat(format string) (OutputFormat, error) {
+	if !slices.Contains(outputFormats, OutputFormat(format)) {
+		return "", fmt.Errorf("invalid output %s, expected one of text, json or ndjson", format)
+	}
+
+	return OutputFormat(format), nil
+}
+
+type Record struct {
+	Command     string   `json:"command"`
+	Action      string   `json:"action,omitempty"`
+	Source      string   `json:"source,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	Status      string   `json:"status"`
+	Layer       string   `json:"layer,omitempty"`
+	Drift       string   `json:"drift,omitempty"`
+	Error       string   `json:"error,omitempty"`
+	Hunks       []string `json:"hunks,omitempty"`
+}
+
+type IReporter interface {
+	Report(record Record)
+	Flush() error
+}
+
+type nopReporter struct{}
+
+func (nopReporter) Report(Record) {}
+
+func (nopReporter) Flush() error {
+	return nil
+}
+
+type jsonReporter struct {
+	out     io.Writer
+	records []Record
+}
+
+func (r *jsonReporter) Report(record Record) {
+	r.records = append(r.records, record)
+}
+
+func (r *jsonReporter) Flush() error {
+	encoder := json.NewEncoder(r.out)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(r.records)
+}
+
+type ndjsonReporter struct {
+	out io.Writer
+}
+
+func (r ndjsonReporter) Report(record Record) {
+	_ = json.NewEncoder(r.out).Encode(record)
+}
+
+func (ndjsonReporter) Flush() error {
+	return nil
+}
+
+func MakeReporter(format OutputFormat, out io.Writer) IReporter {
+	switch format {
+	case OutputFormatJSON:
+		return &jsonReporter{out: out, records: []Record{}}
+	case OutputFormatNDJSON:
+		return ndjsonReporter{out: out}
+	default:
+		return nopReporter{}
+	}
+}
+
+func SplitHunks(diffs string) []string {
+	var hunks []string
+
+	for line := range strings.SplitSeq(strings.TrimSpace(diffs), "\n") {
+		if strings.HasPrefix(line, "@@") {
+			hunks = append(hunks, line)
+
+			continue
+		}
+
+		if len(hunks) > 0 {
+			hunks[len(hunks)-1] += "\n" + line
+		}
+	}
+
+	return hunks
+}
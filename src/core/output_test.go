@@ -0,0 +1,93 @@
+package core_test
+
+import (
+	"bytes"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ParseOutputFormat()", func() {
+	It("should parse valid output formats", func() {
+		for _, format := range []string{"text", "json", "ndjson"} {
+			output, err := core.ParseOutputFormat(format)
+
+			Expect(err).To(BeNil())
+			Expect(output).To(Equal(core.OutputFormat(format)))
+		}
+	})
+
+	It("should return an error for invalid output formats", func() {
+		_, err := core.ParseOutputFormat("xml")
+
+		Expect(err).To(MatchError("invalid output xml, expected one of text, json or ndjson"))
+	})
+})
+
+var _ = Describe("MakeReporter()", func() {
+	record := core.Record{Command: "diff", Source: "/foo", Status: "modified", Hunks: []string{"@@ -1 +1 @@\n-a\n+b"}}
+
+	It("should print all records as a json array on flush", func() {
+		var out bytes.Buffer
+
+		reporter := core.MakeReporter(core.OutputFormatJSON, &out)
+		reporter.Report(record)
+
+		Expect(out.String()).To(BeEmpty())
+		Expect(reporter.Flush()).To(BeNil())
+		Expect(out.String()).To(MatchJSON(
+			`[{"command":"diff","source":"/foo","status":"modified","hunks":["@@ -1 +1 @@\n-a\n+b"]}]`,
+		))
+	})
+
+	It("should print an empty json array if there are no records", func() {
+		var out bytes.Buffer
+
+		Expect(core.MakeReporter(core.OutputFormatJSON, &out).Flush()).To(BeNil())
+		Expect(out.String()).To(Equal("[]\n"))
+	})
+
+	It("should print a record per line with ndjson", func() {
+		var out bytes.Buffer
+
+		reporter := core.MakeReporter(core.OutputFormatNDJSON, &out)
+		reporter.Report(record)
+		reporter.Report(core.Record{Command: "apply", Status: "ok"})
+
+		Expect(out.String()).To(Equal(
+			`{"command":"diff","source":"/foo","status":"modified","hunks":["@@ -1 +1 @@\n-a\n+b"]}` + "\n" +
+				`{"command":"apply","status":"ok"}` + "\n",
+		))
+	})
+
+	It("should not print anything with text", func() {
+		var out bytes.Buffer
+
+		reporter := core.MakeReporter(core.OutputFormatText, &out)
+		reporter.Report(record)
+
+		Expect(reporter.Flush()).To(BeNil())
+		Expect(out.String()).To(BeEmpty())
+	})
+})
+
+var _ = Describe("SplitHunks()", func() {
+	It("should split unified diffs by hunk", func() {
+		Expect(core.SplitHunks("--- a\n+++ b\n@@ -1 +1 @@\n-a\n+b\n@@ -5 +5 @@\n-c\n+d\n")).To(Equal([]string{
+			"@@ -1 +1 @@\n-a\n+b",
+			"@@ -5 +5 @@\n-c\n+d",
+		}))
+		Expect(core.SplitHunks("")).To(BeNil())
+	})
+})
+
+var _ = Describe("MakeLoggerTo()", func() {
+	It("should write to the given writer", func() {
+		var out bytes.Buffer
+
+		core.MakeLoggerTo(&out).Lognl("foo %s", "bar")
+
+		Expect(out.String()).To(Equal("foo bar\n"))
+	})
+})
@@ -0,0 +1,152 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type ScriptKind string
+
+const (
+	ScriptKindOnce     ScriptKind = "once"
+	ScriptKindOnChange ScriptKind = "onchange"
+)
+
+var scriptPrefixes = map[ScriptKind]string{
+	ScriptKindOnce:     "run_once_",
+	ScriptKindOnChange: "run_onchange_",
+}
+
+type Script struct {
+	Name string
+	Path string
+	Kind ScriptKind
+	Hash string
+}
+
+type ScriptState struct {
+	Hash  string    `json:"hash"`
+	RanAt time.Time `json:"ranAt"`
+}
+
+type ScriptsState map[string]ScriptState
+
+func ResolveScriptsDir(repoRoot string) string {
+	return filepath.Join(repoRoot, ".dots", "scripts")
+}
+
+func ResolveScriptsStateFile(stateDir string) string {
+	return filepath.Join(stateDir, "scripts.json")
+}
+
+func resolveScriptKind(name string) (ScriptKind, bool) {
+	for kind, prefix := range scriptPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return kind, true
+		}
+	}
+
+	return "", false
+}
+
+func FindScripts(scriptsDir string) ([]Script, error) {
+	var scripts []Script
+
+	if _, err := os.Stat(scriptsDir); err != nil {
+		return nil, nil
+	}
+
+	err := filepath.WalkDir(scriptsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !d.Type().IsRegular() {
+			return nil
+		}
+
+		kind, ok := resolveScriptKind(d.Name())
+		if !ok {
+			return nil
+		}
+
+		hash, err := HashFile(path)
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(scriptsDir, path)
+		if err != nil {
+			return err
+		}
+
+		scripts = append(scripts, Script{
+			Name: filepath.ToSlash(name),
+			Path: path,
+			Kind: kind,
+			Hash: hash,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return scripts, nil
+}
+
+func (s Script) ShouldRun(state ScriptsState) bool {
+	ran, ok := state[s.Name]
+	if !ok {
+		return true
+	}
+
+	return s.Kind == ScriptKindOnChange && ran.Hash != s.Hash
+}
+
+func (s Script) Hook() ConfigHook {
+	stat, err := os.Stat(s.Path)
+	if err == nil && stat.Mode().Perm()&0o111 != 0 {
+		return ConfigHook{Command: quoteShellArg(s.Path)}
+	}
+
+	return ConfigHook{Command: "sh " + quoteShellArg(s.Path)}
+}
+
+func ReadScriptsState(path string) (ScriptsState, error) {
+	state := ScriptsState{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func WriteScriptsState(path string, state ScriptsState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
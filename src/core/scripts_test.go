@@ -0,0 +1,112 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("FindScripts()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should find run once and run on change scripts", func() {
+		scriptsDir := core.ResolveScriptsDir(workingDir)
+
+		os.MkdirAll(filepath.Join(scriptsDir, "linux"), os.ModePerm)
+		os.WriteFile(filepath.Join(scriptsDir, "run_once_foo.sh"), []byte("foo"), 0o600)
+		os.WriteFile(filepath.Join(scriptsDir, "linux", "run_onchange_bar.sh"), []byte("bar"), 0o600)
+		os.WriteFile(filepath.Join(scriptsDir, "baz.sh"), []byte("baz"), 0o600)
+
+		scripts, err := core.FindScripts(scriptsDir)
+
+		fooHash, _ := core.HashFile(filepath.Join(scriptsDir, "run_once_foo.sh"))
+		barHash, _ := core.HashFile(filepath.Join(scriptsDir, "linux", "run_onchange_bar.sh"))
+
+		Expect(err).To(BeNil())
+		Expect(scripts).To(Equal([]core.Script{
+			{
+				Name: "linux/run_onchange_bar.sh",
+				Path: filepath.Join(scriptsDir, "linux", "run_onchange_bar.sh"),
+				Kind: core.ScriptKindOnChange,
+				Hash: barHash,
+			},
+			{
+				Name: "run_once_foo.sh",
+				Path: filepath.Join(scriptsDir, "run_once_foo.sh"),
+				Kind: core.ScriptKindOnce,
+				Hash: fooHash,
+			},
+		}))
+	})
+
+	It("should return no scripts if the scripts dir does not exists", func() {
+		scripts, err := core.FindScripts(core.ResolveScriptsDir(workingDir))
+
+		Expect(err).To(BeNil())
+		Expect(scripts).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Script.ShouldRun()", func() {
+	It("should run scripts without state", func() {
+		Expect(core.Script{Name: "foo", Kind: core.ScriptKindOnce}.ShouldRun(core.ScriptsState{})).To(BeTrue())
+	})
+
+	It("should run once scripts only once", func() {
+		state := core.ScriptsState{"foo": {Hash: "a"}}
+
+		Expect(core.Script{Name: "foo", Kind: core.ScriptKindOnce, Hash: "b"}.ShouldRun(state)).To(BeFalse())
+	})
+
+	It("should run on change scripts when their hash changes", func() {
+		state := core.ScriptsState{"foo": {Hash: "a"}}
+
+		Expect(core.Script{Name: "foo", Kind: core.ScriptKindOnChange, Hash: "a"}.ShouldRun(state)).To(BeFalse())
+		Expect(core.Script{Name: "foo", Kind: core.ScriptKindOnChange, Hash: "b"}.ShouldRun(state)).To(BeTrue())
+	})
+})
+
+var _ = Describe("ReadScriptsState() / WriteScriptsState()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return an empty state if the state file does not exists", func() {
+		state, err := core.ReadScriptsState(core.ResolveScriptsStateFile(workingDir))
+
+		Expect(err).To(BeNil())
+		Expect(state).To(Equal(core.ScriptsState{}))
+	})
+
+	It("should write and read the state", func() {
+		stateFile := core.ResolveScriptsStateFile(filepath.Join(workingDir, "state"))
+		ranAt := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+
+		err := core.WriteScriptsState(stateFile, core.ScriptsState{"foo": {Hash: "a", RanAt: ranAt}})
+
+		Expect(err).To(BeNil())
+
+		state, err := core.ReadScriptsState(stateFile)
+
+		Expect(err).To(BeNil())
+		Expect(state).To(Equal(core.ScriptsState{"foo": {Hash: "a", RanAt: ranAt}}))
+	})
+})
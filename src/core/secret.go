@@ -0,0 +1,170 @@
+package core
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"filippo.io/age"
+	"filippo.io/age/armor"
+)
+
+const EncryptedSuffix = ".age"
+
+func ResolveIdentityFile(homedir string) string {
+	return filepath.Join(ResolveConfigDir(homedir), "key.txt")
+}
+
+func ResolveRecipientsFile(repoRoot string) string {
+	return filepath.Join(repoRoot, ".dots", "recipients")
+}
+
+func IsEncrypted(path string) bool {
+	return strings.HasSuffix(path, EncryptedSuffix) && filepath.Base(path) != EncryptedSuffix
+}
+
+func EncryptedTarget(path string) string {
+	if !IsEncrypted(path) {
+		return path
+	}
+
+	return strings.TrimSuffix(path, EncryptedSuffix)
+}
+
+func SourceTarget(path string) string {
+	if IsEncrypted(path) {
+		return EncryptedTarget(path)
+	}
+
+	return TemplateTarget(path)
+}
+
+func RecreateDecrypted(from string, to string, content []byte) error {
+	return recreateContent(from, to, content, 0o600)
+}
+
+func LoadIdentities(identityFile string) ([]age.Identity, error) {
+	file, err := os.Open(identityFile)
+	if err != nil {
+		return nil, fmt.Errorf("identity file %s does not exists or is not readable", identityFile)
+	}
+
+	defer file.Close()
+
+	identities, err := age.ParseIdentities(file)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error parsing identity file %s", identityFile), err)
+	}
+
+	return identities, nil
+}
+
+func identitiesRecipients(identities []age.Identity) []age.Recipient {
+	var recipients []age.Recipient
+
+	for _, identity := range identities {
+		if identity, ok := identity.(*age.X25519Identity); ok {
+			recipients = append(recipients, identity.Recipient())
+		}
+	}
+
+	return recipients
+}
+
+func ResolveRecipients(config []string, recipientsFile string) ([]age.Recipient, error) {
+	var recipients []age.Recipient
+
+	if len(config) > 0 {
+		fromConfig, err := age.ParseRecipients(strings.NewReader(strings.Join(config, "\n")))
+		if err != nil {
+			return nil, errors.Join(errors.New("error parsing recipients in the config file"), err)
+		}
+
+		recipients = append(recipients, fromConfig...)
+	}
+
+	file, err := os.Open(recipientsFile)
+	if err != nil {
+		return recipients, nil
+	}
+
+	defer file.Close()
+
+	fromFile, err := age.ParseRecipients(file)
+	if err != nil {
+		return nil, errors.Join(fmt.Errorf("error parsing recipients file %s", recipientsFile), err)
+	}
+
+	return append(recipients, fromFile...), nil
+}
+
+func Decrypt(content []byte, identityFile string) ([]byte, error) {
+	identities, err := LoadIdentities(identityFile)
+	if err != nil {
+		return nil, err
+	}
+
+	var src io.Reader = bytes.NewReader(content)
+
+	if bytes.HasPrefix(bytes.TrimSpace(content), []byte(armor.Header)) {
+		src = armor.NewReader(bufio.NewReader(src))
+	}
+
+	reader, err := age.Decrypt(src, identities...)
+	if err != nil {
+		return nil, err
+	}
+
+	return io.ReadAll(reader)
+}
+
+func DecryptFile(path string, identityFile string) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return Decrypt(content, identityFile)
+}
+
+func Encrypt(content []byte, identityFile string, recipients []age.Recipient) ([]byte, error) {
+	if len(recipients) <= 0 {
+		identities, err := LoadIdentities(identityFile)
+		if err != nil {
+			return nil, err
+		}
+
+		recipients = identitiesRecipients(identities)
+		if len(recipients) <= 0 {
+			return nil, fmt.Errorf("identity file %s has no identities to encrypt to", identityFile)
+		}
+	}
+
+	var encrypted bytes.Buffer
+
+	armored := armor.NewWriter(&encrypted)
+
+	writer, err := age.Encrypt(armored, recipients...)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := writer.Write(content); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+
+	if err := armored.Close(); err != nil {
+		return nil, err
+	}
+
+	return encrypted.Bytes(), nil
+}
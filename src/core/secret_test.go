@@ -0,0 +1,166 @@
+package core_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	"filippo.io/age"
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsEncrypted()", func() {
+	It("should check the encrypted suffix", func() {
+		Expect(core.IsEncrypted("/foo/.netrc.age")).To(BeTrue())
+		Expect(core.IsEncrypted("/foo/.netrc")).To(BeFalse())
+		Expect(core.IsEncrypted("/foo/.age")).To(BeFalse())
+	})
+})
+
+var _ = Describe("SourceTarget()", func() {
+	It("should strip the encrypted or template suffix", func() {
+		Expect(core.SourceTarget("/foo/.netrc.age")).To(Equal("/foo/.netrc"))
+		Expect(core.SourceTarget("/foo/.gitconfig.tmpl")).To(Equal("/foo/.gitconfig"))
+		Expect(core.SourceTarget("/foo/.gitconfig")).To(Equal("/foo/.gitconfig"))
+	})
+})
+
+var _ = Describe("Encrypt() / DecryptFile()", func() {
+	var workingDir string
+	var identityFile string
+	var identity *age.X25519Identity
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		identityFile = filepath.Join(workingDir, "key.txt")
+		identity, _ = age.GenerateX25519Identity()
+
+		os.WriteFile(identityFile, []byte(identity.String()+"\n"), 0o600)
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should encrypt to the identity recipients and decrypt it back", func() {
+		path := filepath.Join(workingDir, ".netrc.age")
+
+		encrypted, err := core.Encrypt([]byte("foo"), identityFile, nil)
+
+		Expect(err).To(BeNil())
+		Expect(string(encrypted)).To(HavePrefix("-----BEGIN AGE ENCRYPTED FILE-----"))
+
+		os.WriteFile(path, encrypted, 0o600)
+
+		content, err := core.DecryptFile(path, identityFile)
+
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("foo"))
+	})
+
+	It("should encrypt to all the provided recipients instead of the identity recipients", func() {
+		path := filepath.Join(workingDir, ".netrc.age")
+		other, _ := age.GenerateX25519Identity()
+		otherIdentityFile := filepath.Join(workingDir, "other.txt")
+
+		os.WriteFile(otherIdentityFile, []byte(other.String()+"\n"), 0o600)
+
+		encrypted, err := core.Encrypt(
+			[]byte("foo"),
+			filepath.Join(workingDir, "foo.txt"),
+			[]age.Recipient{identity.Recipient(), other.Recipient()},
+		)
+
+		Expect(err).To(BeNil())
+
+		os.WriteFile(path, encrypted, 0o600)
+
+		for _, file := range []string{identityFile, otherIdentityFile} {
+			content, err := core.DecryptFile(path, file)
+
+			Expect(err).To(BeNil())
+			Expect(string(content)).To(Equal("foo"))
+		}
+	})
+
+	It("should decrypt binary encrypted files", func() {
+		path := filepath.Join(workingDir, ".netrc.age")
+
+		var encrypted bytes.Buffer
+
+		writer, _ := age.Encrypt(&encrypted, identity.Recipient())
+		writer.Write([]byte("foo"))
+		writer.Close()
+		os.WriteFile(path, encrypted.Bytes(), 0o600)
+
+		content, err := core.DecryptFile(path, identityFile)
+
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("foo"))
+	})
+
+	It("should return an error if the identity does not match", func() {
+		path := filepath.Join(workingDir, ".netrc.age")
+		other, _ := age.GenerateX25519Identity()
+
+		var encrypted bytes.Buffer
+
+		writer, _ := age.Encrypt(&encrypted, other.Recipient())
+		writer.Write([]byte("foo"))
+		writer.Close()
+		os.WriteFile(path, encrypted.Bytes(), 0o600)
+
+		_, err := core.DecryptFile(path, identityFile)
+
+		Expect(err).ToNot(BeNil())
+	})
+
+	It("should return an error if the identity file does not exists", func() {
+		_, err := core.Encrypt([]byte("foo"), filepath.Join(workingDir, "foo.txt"), nil)
+
+		Expect(err).To(MatchError(
+			"identity file " + filepath.Join(workingDir, "foo.txt") + " does not exists or is not readable",
+		))
+	})
+})
+
+var _ = Describe("ResolveRecipients()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should resolve the recipients from the config and the recipients file", func() {
+		first, _ := age.GenerateX25519Identity()
+		second, _ := age.GenerateX25519Identity()
+		recipientsFile := core.ResolveRecipientsFile(workingDir)
+
+		os.MkdirAll(filepath.Dir(recipientsFile), os.ModePerm)
+		os.WriteFile(recipientsFile, []byte("# laptop\n"+second.Recipient().String()+"\n"), 0o600)
+
+		recipients, err := core.ResolveRecipients([]string{first.Recipient().String()}, recipientsFile)
+
+		Expect(err).To(BeNil())
+		Expect(recipients).To(Equal([]age.Recipient{first.Recipient(), second.Recipient()}))
+	})
+
+	It("should resolve no recipients if none are configured", func() {
+		recipients, err := core.ResolveRecipients(nil, core.ResolveRecipientsFile(workingDir))
+
+		Expect(err).To(BeNil())
+		Expect(recipients).To(BeEmpty())
+	})
+
+	It("should return an error if a recipient is not valid", func() {
+		_, err := core.ResolveRecipients([]string{"foo"}, core.ResolveRecipientsFile(workingDir))
+
+		Expect(err).ToNot(BeNil())
+	})
+})
@@ -0,0 +1,160 @@
+package core
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type FileDrift string
+
+const (
+	FileDriftNone  FileDrift = ""
+	FileDriftLocal FileDrift = "changed-locally"
+	FileDriftRepo  FileDrift = "changed-in-repo"
+	FileDriftBoth  FileDrift = "both-changed"
+)
+
+type FileState struct {
+	Source          string      `json:"source"`
+	SourceHash      string      `json:"sourceHash"`
+	DestinationHash string      `json:"destinationHash"`
+	Mode            fs.FileMode `json:"mode"`
+	LinkTarget      string      `json:"linkTarget,omitempty"`
+	Command         string      `json:"command"`
+	UpdatedAt       time.Time   `json:"updatedAt"`
+}
+
+type FilesState map[string]FileState
+
+func ResolveFilesStateFile(stateDir string) string {
+	return filepath.Join(stateDir, "files.json")
+}
+
+func filesStateKey(homedir string, path string) string {
+	return strings.TrimPrefix(strings.TrimPrefix(path, homedir), string(filepath.Separator))
+}
+
+func ReadFilesState(path string) (FilesState, error) {
+	state := FilesState{}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return state, nil
+		}
+
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, err
+	}
+
+	return state, nil
+}
+
+func WriteFilesState(path string, state FilesState) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, 0o600)
+}
+
+func MakeFileState(source string, destination string, command string) (FileState, error) {
+	sourceHash, err := HashFile(source)
+	if err != nil {
+		return FileState{}, err
+	}
+
+	destinationHash, err := HashFile(destination)
+	if err != nil {
+		return FileState{}, err
+	}
+
+	stat, err := os.Stat(destination)
+	if err != nil {
+		return FileState{}, err
+	}
+
+	return FileState{
+		Source:          source,
+		SourceHash:      sourceHash,
+		DestinationHash: destinationHash,
+		Mode:            stat.Mode().Perm(),
+		Command:         command,
+		UpdatedAt:       time.Now().UTC(),
+	}, nil
+}
+
+func MakeLinkState(source string, destination string, command string) (FileState, error) {
+	target, err := os.Readlink(destination)
+	if err != nil {
+		return FileState{}, err
+	}
+
+	return FileState{
+		Source:     source,
+		LinkTarget: target,
+		Command:    command,
+		UpdatedAt:  time.Now().UTC(),
+	}, nil
+}
+
+func (s FilesState) Get(homedir string, path string) (FileState, bool) {
+	state, ok := s[filesStateKey(homedir, path)]
+
+	return state, ok
+}
+
+func (s FilesState) Set(homedir string, path string, state FileState) {
+	s[filesStateKey(homedir, path)] = state
+}
+
+func (s FilesState) Delete(homedir string, path string) {
+	delete(s, filesStateKey(homedir, path))
+}
+
+func resolveFileDrift(changedLocally bool, changedInRepo bool) FileDrift {
+	switch {
+	case changedLocally && changedInRepo:
+		return FileDriftBoth
+	case changedLocally:
+		return FileDriftLocal
+	case changedInRepo:
+		return FileDriftRepo
+	default:
+		return FileDriftNone
+	}
+}
+
+func (s FileState) Drift(source string, destination string) FileDrift {
+	if s.LinkTarget != "" {
+		target, _ := os.Readlink(destination)
+
+		return resolveFileDrift(target != s.LinkTarget, source != s.Source)
+	}
+
+	sourceHash, _ := HashFile(source)
+	destinationHash, _ := HashFile(destination)
+
+	changedLocally := destinationHash != s.DestinationHash
+
+	if stat, err := os.Stat(destination); err == nil && stat.Mode().Perm() != s.Mode {
+		changedLocally = true
+	}
+
+	changedInRepo := sourceHash != s.SourceHash || source != s.Source
+
+	return resolveFileDrift(changedLocally, changedInRepo)
+}
@@ -0,0 +1,133 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("ReadFilesState() / WriteFilesState()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should return an empty state if the state file does not exists", func() {
+		state, err := core.ReadFilesState(core.ResolveFilesStateFile(workingDir))
+
+		Expect(err).To(BeNil())
+		Expect(state).To(Equal(core.FilesState{}))
+	})
+
+	It("should write and read the state keyed by the path relative to the homedir", func() {
+		stateFile := core.ResolveFilesStateFile(filepath.Join(workingDir, "state"))
+		state := core.FilesState{}
+
+		state.Set("/home/foo", "/home/foo/.bashrc", core.FileState{Source: "/dotfiles/.bashrc", Mode: 0o600})
+
+		Expect(core.WriteFilesState(stateFile, state)).To(BeNil())
+
+		state, err := core.ReadFilesState(stateFile)
+
+		Expect(err).To(BeNil())
+		Expect(state).To(HaveKey(".bashrc"))
+
+		fileState, ok := state.Get("/home/foo", "/home/foo/.bashrc")
+
+		Expect(ok).To(BeTrue())
+		Expect(fileState.Source).To(Equal("/dotfiles/.bashrc"))
+	})
+})
+
+var _ = Describe("FileState.Drift()", func() {
+	var workingDir string
+	var source string
+	var destination string
+	var state core.FileState
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+		source = filepath.Join(workingDir, "source")
+		destination = filepath.Join(workingDir, "destination")
+
+		os.WriteFile(source, []byte("foo"), 0o600)
+		os.WriteFile(destination, []byte("foo"), 0o600)
+
+		state, _ = core.MakeFileState(source, destination, "apply")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should not drift if nothing changed", func() {
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftNone))
+	})
+
+	It("should drift locally if the destination content or mode changed", func() {
+		os.Chmod(destination, 0o700)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftLocal))
+
+		os.Chmod(destination, 0o600)
+		os.WriteFile(destination, []byte("bar"), 0o600)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftLocal))
+	})
+
+	It("should drift in repo if the source changed", func() {
+		os.WriteFile(source, []byte("bar"), 0o600)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftRepo))
+	})
+
+	It("should drift on both sides if both changed", func() {
+		os.WriteFile(source, []byte("bar"), 0o600)
+		os.WriteFile(destination, []byte("baz"), 0o600)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftBoth))
+	})
+})
+
+var _ = Describe("MakeLinkState()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should record the link target and drift locally when it changes", func() {
+		source := filepath.Join(workingDir, "source")
+		destination := filepath.Join(workingDir, "destination")
+
+		os.WriteFile(source, []byte("foo"), 0o600)
+		os.Symlink(source, destination)
+
+		state, err := core.MakeLinkState(source, destination, "apply")
+
+		Expect(err).To(BeNil())
+		Expect(state.LinkTarget).To(Equal(source))
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftNone))
+
+		os.WriteFile(source, []byte("bar"), 0o600)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftNone))
+
+		os.Remove(destination)
+		os.Symlink(filepath.Join(workingDir, "other"), destination)
+
+		Expect(state.Drift(source, destination)).To(Equal(core.FileDriftLocal))
+	})
+})
@@ -0,0 +1,114 @@
+package core
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"os/user"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"text/template"
+)
+
+const TemplateSuffix = ".tmpl"
+
+type TemplateData struct {
+	Hostname string
+	OS       string
+	Arch     string
+	User     string
+	Homedir  string
+	Vars     map[string]any
+}
+
+func ResolveTemplateData(homedir string, vars map[string]any) TemplateData {
+	hostname, _ := os.Hostname()
+	username := ""
+
+	if current, err := user.Current(); err == nil {
+		username = current.Username
+	}
+
+	if vars == nil {
+		vars = map[string]any{}
+	}
+
+	return TemplateData{
+		Hostname: hostname,
+		OS:       runtime.GOOS,
+		Arch:     runtime.GOARCH,
+		User:     username,
+		Homedir:  homedir,
+		Vars:     vars,
+	}
+}
+
+func IsTemplate(path string) bool {
+	return strings.HasSuffix(path, TemplateSuffix) && filepath.Base(path) != TemplateSuffix
+}
+
+func TemplateTarget(path string) string {
+	if !IsTemplate(path) {
+		return path
+	}
+
+	return strings.TrimSuffix(path, TemplateSuffix)
+}
+
+func RenderTemplate(path string, data TemplateData) ([]byte, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).
+		Option("missingkey=error").
+		Funcs(template.FuncMap{"env": os.Getenv}).
+		Parse(string(content))
+	if err != nil {
+		return nil, err
+	}
+
+	var rendered bytes.Buffer
+
+	if err := tmpl.Execute(&rendered, data); err != nil {
+		return nil, err
+	}
+
+	return rendered.Bytes(), nil
+}
+
+func recreateContent(from string, to string, content []byte, mask fs.FileMode) error {
+	stat, err := os.Stat(from)
+	if err != nil {
+		return err
+	}
+
+	if err := recreateDir(filepath.Dir(from), filepath.Dir(to)); err != nil {
+		return err
+	}
+
+	if err := removeSymlink(to); err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(to, content, stat.Mode().Perm()&mask); err != nil {
+		return err
+	}
+
+	return os.Chmod(to, stat.Mode().Perm()&mask)
+}
+
+func RecreateRendered(from string, to string, content []byte) error {
+	return recreateContent(from, to, content, fs.ModePerm)
+}
+
+func ContentEqual(path string, content []byte) bool {
+	current, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(current, content)
+}
@@ -0,0 +1,95 @@
+package core_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"github.com/m4rc3l05/dots/src/core"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("IsTemplate()", func() {
+	It("should check the template suffix", func() {
+		Expect(core.IsTemplate("/foo/.gitconfig.tmpl")).To(BeTrue())
+		Expect(core.IsTemplate("/foo/.gitconfig")).To(BeFalse())
+		Expect(core.IsTemplate("/foo/.tmpl")).To(BeFalse())
+	})
+})
+
+var _ = Describe("TemplateTarget()", func() {
+	It("should strip the template suffix", func() {
+		Expect(core.TemplateTarget("/foo/.gitconfig.tmpl")).To(Equal("/foo/.gitconfig"))
+		Expect(core.TemplateTarget("/foo/.gitconfig")).To(Equal("/foo/.gitconfig"))
+	})
+})
+
+var _ = Describe("RenderTemplate()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should render built-ins, vars and env lookups", func() {
+		path := filepath.Join(workingDir, "foo.tmpl")
+
+		GinkgoT().Setenv("DOTS_TEST_FOO", "baz")
+		os.WriteFile(
+			path,
+			[]byte(`{{ .Hostname }} {{ .OS }} {{ .Arch }} {{ .User }} {{ .Vars.email }} {{ env "DOTS_TEST_FOO" }}`),
+			0o600,
+		)
+
+		content, err := core.RenderTemplate(path, core.TemplateData{
+			Hostname: "host",
+			OS:       "linux",
+			Arch:     "amd64",
+			User:     "foo",
+			Vars:     map[string]any{"email": "foo@bar.baz"},
+		})
+
+		Expect(err).To(BeNil())
+		Expect(string(content)).To(Equal("host linux amd64 foo foo@bar.baz baz"))
+	})
+
+	It("should return an error for missing vars", func() {
+		path := filepath.Join(workingDir, "foo.tmpl")
+
+		os.WriteFile(path, []byte(`{{ .Vars.email }}`), 0o600)
+
+		_, err := core.RenderTemplate(path, core.TemplateData{Vars: map[string]any{}})
+
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+var _ = Describe("RecreateRendered()", func() {
+	var workingDir string
+
+	_ = BeforeEach(func() {
+		workingDir, _ = os.MkdirTemp(os.TempDir(), "wd-*")
+	})
+
+	_ = AfterEach(func() {
+		os.RemoveAll(workingDir)
+	})
+
+	It("should write the content with the template mode", func() {
+		from := filepath.Join(workingDir, "from", "foo.tmpl")
+		to := filepath.Join(workingDir, "to", "foo")
+
+		os.MkdirAll(filepath.Dir(from), os.ModePerm)
+		os.WriteFile(from, []byte("{{ .OS }}"), 0o750)
+
+		err := core.RecreateRendered(from, to, []byte("linux"))
+
+		Expect(err).To(BeNil())
+		Expect(core.ContentEqual(to, []byte("linux"))).To(BeTrue())
+		Expect(core.ModesEqual(from, to)).To(BeTrue())
+	})
+})
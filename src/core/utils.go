@@ -1,8 +1,12 @@
 package core
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path/filepath"
 	"strings"
@@ -20,26 +24,189 @@ func genEnvOrNil(key string) *string {
 	return &env
 }
 
-func resolveDotfilesFilesDirFromEnv(fallback *string) *string {
-	fromEnv := genEnvOrNil("DOTS_DOTFILES_FILES_DIR")
+func recreateDir(from string, to string) error {
+	if _, err := os.Stat(to); err == nil {
+		return nil
+	}
+
+	if err := recreateDir(filepath.Dir(from), filepath.Dir(to)); err != nil {
+		return err
+	}
+
+	mode := os.ModePerm
+
+	if stat, err := os.Stat(from); err == nil && stat.IsDir() {
+		mode = stat.Mode().Perm()
+	}
+
+	if err := os.Mkdir(to, mode); err != nil && !errors.Is(err, fs.ErrExist) {
+		return err
+	}
+
+	return os.Chmod(to, mode)
+}
+
+func copyFile(from string, to string, mode fs.FileMode) error {
+	fromFile, err := os.Open(from)
+	if err != nil {
+		return err
+	}
 
-	if fromEnv == nil {
-		return fallback
+	defer fromFile.Close()
+
+	toFile, err := os.OpenFile(to, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, mode)
+	if err != nil {
+		return err
 	}
 
-	return fromEnv
+	defer toFile.Close()
+
+	if _, err := io.Copy(toFile, fromFile); err != nil {
+		return err
+	}
+
+	return toFile.Chmod(mode)
+}
+
+func removeSymlink(path string) error {
+	stat, err := os.Lstat(path)
+	if err != nil || stat.Mode()&fs.ModeSymlink == 0 {
+		return nil
+	}
+
+	return os.Remove(path)
 }
 
 func RecreateFile(from string, to string) error {
-	if err := os.MkdirAll(filepath.Dir(to), os.ModePerm); err != nil {
+	stat, err := os.Stat(from)
+	if err != nil {
 		return err
 	}
 
-	if err := fsutil.CopyFile(from, to); err != nil {
+	if err := recreateDir(filepath.Dir(from), filepath.Dir(to)); err != nil {
 		return err
 	}
 
-	return nil
+	if err := removeSymlink(to); err != nil {
+		return err
+	}
+
+	return copyFile(from, to, stat.Mode().Perm())
+}
+
+func RecreateSymlink(from string, to string, target string) error {
+	if err := recreateDir(filepath.Dir(from), filepath.Dir(to)); err != nil {
+		return err
+	}
+
+	if stat, err := os.Lstat(to); err == nil {
+		if stat.IsDir() {
+			return fmt.Errorf("path %s is a directory", to)
+		}
+
+		if err := os.Remove(to); err != nil {
+			return err
+		}
+	}
+
+	return os.Symlink(target, to)
+}
+
+func RecreatePath(from string, to string) error {
+	if !IsSymlink(from) {
+		return RecreateFile(from, to)
+	}
+
+	target, err := os.Readlink(from)
+	if err != nil {
+		return err
+	}
+
+	return RecreateSymlink(from, to, target)
+}
+
+func IsSymlink(path string) bool {
+	stat, err := os.Lstat(path)
+	if err != nil {
+		return false
+	}
+
+	return stat.Mode()&fs.ModeSymlink != 0
+}
+
+func IsSubpath(path string, root string) bool {
+	return path == root || strings.HasPrefix(path, root+string(filepath.Separator))
+}
+
+func MapLinkTarget(target string, fromRoot string, toRoot string) string {
+	if !filepath.IsAbs(target) || !IsSubpath(target, fromRoot) {
+		return target
+	}
+
+	if IsSubpath(target, toRoot) && len(toRoot) > len(fromRoot) {
+		return target
+	}
+
+	return filepath.Join(toRoot, strings.TrimPrefix(target, fromRoot))
+}
+
+func ModesEqual(a string, b string) bool {
+	aStat, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+
+	bStat, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	return aStat.Mode().Perm() == bStat.Mode().Perm()
+}
+
+func HashFile(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+
+	defer file.Close()
+
+	hash := sha256.New()
+
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+func FilesEqual(a string, b string) bool {
+	aStat, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+
+	bStat, err := os.Stat(b)
+	if err != nil {
+		return false
+	}
+
+	if aStat.Size() != bStat.Size() {
+		return false
+	}
+
+	aHash, err := HashFile(a)
+	if err != nil {
+		return false
+	}
+
+	bHash, err := HashFile(b)
+	if err != nil {
+		return false
+	}
+
+	return aHash == bHash
 }
 
 func IsPathReadable(path string) bool {
@@ -86,7 +253,7 @@ func ResolveHomeDir() (string, error) {
 }
 
 func ResolveDotfilesFilesDir(dotfilesFilesDirPath *string) (string, error) {
-	dir := resolveDotfilesFilesDirFromEnv(dotfilesFilesDirPath)
+	dir := dotfilesFilesDirPath
 
 	if dir == nil {
 		return "", errors.New("dotfiles files directory path not provided")
@@ -107,6 +274,22 @@ func ResolveDotfilesFilesDir(dotfilesFilesDirPath *string) (string, error) {
 	return dotfilesFilesDir, nil
 }
 
+func ResolveTargetDir(targetDirPath string) (string, error) {
+	targetDir, err := filepath.Abs(targetDirPath)
+	if err != nil {
+		return "", err
+	}
+
+	if !fsutil.DirExist(targetDir) || !IsPathReadable(targetDir) {
+		return "", fmt.Errorf(
+			"target dir %s does not exists or is not a directory or is not readable",
+			targetDir,
+		)
+	}
+
+	return targetDir, nil
+}
+
 func LogErrors(logger ILogger, err error, n int) {
 	wrapArr, ok := err.(interface{ Unwrap() []error })
 
@@ -2,6 +2,7 @@ package core_test
 
 import (
 	"errors"
+	"fmt"
 	"os"
 
 	"github.com/gookit/goutil/fsutil"
@@ -58,6 +59,171 @@ var _ = Describe("RecreateFile()", func() {
 			Expect(string(fsutil.ReadAll(workingDir + "/4/5/6"))).To(Equal("foo"))
 		},
 	)
+	It("should preserve the file mode", func() {
+		os.MkdirAll(workingDir+"/1", os.ModePerm)
+		os.WriteFile(workingDir+"/1/2", []byte("foo"), 0o755)
+		os.Chmod(workingDir+"/1/2", 0o755)
+
+		err := core.RecreateFile(workingDir+"/1/2", workingDir+"/3/4")
+
+		Expect(err).To(BeNil())
+		stat, _ := os.Stat(workingDir + "/3/4")
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o755)))
+	})
+
+	It("should preserve the file mode when the file exists in destination", func() {
+		os.MkdirAll(workingDir+"/1", os.ModePerm)
+		os.MkdirAll(workingDir+"/3", os.ModePerm)
+		os.WriteFile(workingDir+"/1/2", []byte("foo"), 0o600)
+		os.WriteFile(workingDir+"/3/4", []byte("bar"), 0o644)
+
+		err := core.RecreateFile(workingDir+"/1/2", workingDir+"/3/4")
+
+		Expect(err).To(BeNil())
+		stat, _ := os.Stat(workingDir + "/3/4")
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o600)))
+	})
+
+	It("should create parent directories with the mode of the source directories", func() {
+		os.MkdirAll(workingDir+"/1/2", os.ModePerm)
+		os.Chmod(workingDir+"/1", 0o750)
+		os.Chmod(workingDir+"/1/2", 0o700)
+		os.WriteFile(workingDir+"/1/2/3", []byte("foo"), 0o600)
+		os.MkdirAll(workingDir+"/4", os.ModePerm)
+
+		err := core.RecreateFile(workingDir+"/1/2/3", workingDir+"/4/5/6/3")
+
+		Expect(err).To(BeNil())
+		stat, _ := os.Stat(workingDir + "/4/5")
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o750)))
+		stat, _ = os.Stat(workingDir + "/4/5/6")
+		Expect(stat.Mode().Perm()).To(Equal(os.FileMode(0o700)))
+	})
+})
+
+var _ = Describe("HashFile()", func() {
+	It("should return the sha256 of the file content", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+
+		hash, err := core.HashFile(workingDir + "/1")
+
+		Expect(err).To(BeNil())
+		Expect(hash).To(Equal("2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"))
+	})
+
+	It("should return an error if the file does not exist", func() {
+		_, err := core.HashFile(workingDir + "/1")
+
+		Expect(err).ToNot(BeNil())
+	})
+})
+
+var _ = Describe("FilesEqual()", func() {
+	It("should return true if files have the same content", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+		os.WriteFile(workingDir+"/2", []byte("foo"), 0o644)
+
+		Expect(core.FilesEqual(workingDir+"/1", workingDir+"/2")).To(BeTrue())
+	})
+
+	It("should return false if files have different content", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+		os.WriteFile(workingDir+"/2", []byte("bar"), 0o644)
+
+		Expect(core.FilesEqual(workingDir+"/1", workingDir+"/2")).To(BeFalse())
+	})
+
+	It("should return false if files have different sizes", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+		os.WriteFile(workingDir+"/2", []byte("foobar"), 0o644)
+
+		Expect(core.FilesEqual(workingDir+"/1", workingDir+"/2")).To(BeFalse())
+	})
+
+	It("should return false if a file does not exist", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+
+		Expect(core.FilesEqual(workingDir+"/1", workingDir+"/2")).To(BeFalse())
+	})
+})
+
+var _ = Describe("ModesEqual()", func() {
+	It("should return true if files have the same mode", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o600)
+		os.WriteFile(workingDir+"/2", []byte("bar"), 0o600)
+
+		Expect(core.ModesEqual(workingDir+"/1", workingDir+"/2")).To(BeTrue())
+	})
+
+	It("should return false if files have different modes", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o600)
+		os.WriteFile(workingDir+"/2", []byte("foo"), 0o600)
+		os.Chmod(workingDir+"/2", 0o755)
+
+		Expect(core.ModesEqual(workingDir+"/1", workingDir+"/2")).To(BeFalse())
+	})
+})
+
+var _ = Describe("RecreateSymlink()", func() {
+	It("should create a symlink replacing an existing file", func() {
+		os.MkdirAll(workingDir+"/1", os.ModePerm)
+		os.MkdirAll(workingDir+"/2", os.ModePerm)
+		os.WriteFile(workingDir+"/2/3", []byte("foo"), 0o644)
+
+		err := core.RecreateSymlink(workingDir+"/1/3", workingDir+"/2/3", "foo")
+
+		Expect(err).To(BeNil())
+		Expect(core.IsSymlink(workingDir + "/2/3")).To(BeTrue())
+		Expect(os.Readlink(workingDir + "/2/3")).To(Equal("foo"))
+	})
+
+	It("should not replace a directory", func() {
+		os.MkdirAll(workingDir+"/2/3", os.ModePerm)
+
+		err := core.RecreateSymlink(workingDir+"/1/3", workingDir+"/2/3", "foo")
+
+		Expect(err).To(MatchError(fmt.Sprintf("path %s is a directory", workingDir+"/2/3")))
+	})
+})
+
+var _ = Describe("RecreateFile() with symlinks", func() {
+	It("should replace a symlink in destination instead of writing through it", func() {
+		os.WriteFile(workingDir+"/1", []byte("foo"), 0o644)
+		os.WriteFile(workingDir+"/2", []byte("bar"), 0o644)
+		os.Symlink(workingDir+"/2", workingDir+"/3")
+
+		err := core.RecreateFile(workingDir+"/1", workingDir+"/3")
+
+		Expect(err).To(BeNil())
+		Expect(core.IsSymlink(workingDir + "/3")).To(BeFalse())
+		Expect(string(fsutil.ReadAll(workingDir + "/3"))).To(Equal("foo"))
+		Expect(string(fsutil.ReadAll(workingDir + "/2"))).To(Equal("bar"))
+	})
+})
+
+var _ = Describe("MapLinkTarget()", func() {
+	It("should not map relative targets", func() {
+		Expect(core.MapLinkTarget("foo", "/home/foo", "/dotfiles")).To(Equal("foo"))
+	})
+
+	It("should not map targets outside `fromRoot`", func() {
+		Expect(core.MapLinkTarget("/etc/foo", "/home/foo", "/dotfiles")).To(Equal("/etc/foo"))
+	})
+
+	It("should map targets inside `fromRoot`", func() {
+		Expect(
+			core.MapLinkTarget("/home/foo/src/nvim", "/home/foo", "/home/foo/.dotfiles/home"),
+		).To(Equal("/home/foo/.dotfiles/home/src/nvim"))
+		Expect(
+			core.MapLinkTarget("/home/foo/.dotfiles/home/.bashrc", "/home/foo/.dotfiles/home", "/home/foo"),
+		).To(Equal("/home/foo/.bashrc"))
+	})
+
+	It("should not map targets already inside a nested `toRoot`", func() {
+		Expect(
+			core.MapLinkTarget("/home/foo/.dotfiles/home/.bashrc", "/home/foo", "/home/foo/.dotfiles/home"),
+		).To(Equal("/home/foo/.dotfiles/home/.bashrc"))
+	})
 })
 
 var _ = Describe("IsPathReadable()", func() {
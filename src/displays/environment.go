@@ -4,15 +4,48 @@ import (
 	"strings"
 
 	"github.com/fatih/color"
+	"github.com/m4rc3l05/dots/src/core"
 )
 
-func (d Displays) Environment(homedir string, dotfilesFilesDir string) {
+func formatSetting(setting core.Setting) string {
+	return color.BlueString(setting.Value) + " " + color.HiBlackString("(%s)", setting.Source)
+}
+
+func (d Displays) Environment(settings core.Settings) {
+	configFile := settings.ConfigFile
+
+	if configFile == "" {
+		configFile = "none"
+	}
+
+	layers := make([]string, 0, len(settings.Layers))
+
+	for _, layer := range settings.Layers {
+		layers = append(layers, layer.Name)
+	}
+
 	d.Logger.Lognl(strings.TrimSpace(`
 -----------------------
 Environment:
-
 HOME:               %s
+CONFIG FILE:        %s
 DOTFILES FILES DIR: %s
+TARGET DIR:         %s
+MODE:               %s
+PROFILES:           %s
+IDENTITY:           %s
+MERGE TOOL:         %s
+LAYERS:             %s
 -----------------------
-`), color.BlueString(homedir), color.BlueString(dotfilesFilesDir))
+`),
+		color.BlueString(settings.Homedir),
+		color.BlueString(configFile),
+		formatSetting(settings.DotfilesFilesDir),
+		formatSetting(settings.TargetDir),
+		formatSetting(settings.Mode),
+		formatSetting(settings.Profiles),
+		formatSetting(settings.Identity),
+		formatSetting(settings.MergeTool),
+		color.BlueString(strings.Join(layers, ", ")),
+	)
 }
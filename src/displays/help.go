@@ -21,27 +21,228 @@ Usage: %s %s %s %s
   --version                               Display version
 
   --dotfilesFilesDir <path>               Dotfiles files directory path to be used as the place where the ~/ will be mapped to.
-                                          This directory should be version controlled in order to keep an history of the changes.
-                                          It can also be controled with "DOTS_DOTFILES_FILES_DIR" env var, and takes precedence over the cmd flag.
+                                          This directory should be version controlled in order to keep an history of the changes,
+                                          the git, commit and sync commands work on the git repository of its parent directory.
+                                          It can also be controled with "DOTS_DOTFILES_FILES_DIR" env var or "filesDir" in the config file.
                                           It defaults to "~/.dotfiles/home".
 
-  --printEnv <true/false>                 Prints homedir and dotfiles files dir values.
+  --targetDir <path>                      Directory where the dotfiles files are applied to and adopted from.
+                                          It can also be controled with "DOTS_TARGET_DIR" env var or "targetDir" in the config file.
+                                          It defaults to "~/".
+
+  --printEnv <true/false>                 Prints homedir, config file, dotfiles files dir, target dir and mode values,
+                                          and where each value came from (flag, env, config or default).
 
   --color <true/false>                    Colors output. Enabled by default.
 
+  --dryRun                                Prints the planned changes (create, overwrite or unchanged) of apply, adopt, add and forget,
+                                          without touching the filesystem.
+
+  --output <text/json/ndjson>             Output format of diff, status, apply, adopt, add, forget, managed and unmanaged. Defaults to "text".
+                                          With "json" an array of records is printed once the command finishes, and with "ndjson"
+                                          one record per line is printed as files are processed. Records have "command", "action",
+                                          "source", "destination", "status", "error", for diff, "hunks" and, for managed, "layer" fields.
+                                          Records replace the per-file text output, summaries and other messages are printed to stderr without colors.
+
+  --mode <copy/link>                      Deployment mode used by apply and diff. Defaults to "copy".
+                                          It can also be controled with "DOTS_MODE" env var or "mode" in the config file.
+                                          With "link", apply creates symlinks in ~/ pointing to the dotfiles files,
+                                          so changes are reflected in the dotfiles files directory and adopt is not needed.
+
+  --force                                 Allows apply in "link" mode to replace regular files with symlinks.
+
+  --prune                                 Makes apply remove files from ~/ that it applied before but no longer exist in the dotfiles files.
+
+  --interactive                           Shows the diff of each changed file on apply and adopt and asks what to do with it:
+                                          "y" applies/adopts it, "n" skips it, "r" reverses it (adopts it on apply and applies it on adopt),
+                                          "m" opens a merge of both versions in "$VISUAL" or "$EDITOR" (defaults to "vi") and uses the result
+                                          for both sides, and "q" skips the remaining files.
+
+  --profile <name,...>                    Comma separated list of profiles to layer over the dotfiles files directory.
+                                          It can also be controled with "DOTS_PROFILE" env var or "profiles" in the config file.
+
+  --depth <n>                             Max directory depth walked by unmanaged, 0 means no limit. Defaults to 3.
+
+  --identity <path>                       Age identity file used to decrypt and encrypt ".age" files.
+                                          It can also be controled with "DOTS_IDENTITY" env var or "identity" in the config file.
+                                          It defaults to "$XDG_CONFIG_HOME/dots/key.txt", or "~/.config/dots/key.txt".
+
 %s:
-  diff                                    Diffs the user's dotfiles files with the ~/ files.
+  diff                                    Diffs the user's dotfiles files with the ~/ files, including file mode differences.
+                                          Lines starting with "-" come from the dotfiles files and lines starting with "+" from ~/.
+                                          Files are reported as modified, only in dotfiles (would be created by apply)
+                                          or only in ~/ within tracked directories (would be picked up by adopt).
+                                          Tracked directories are the ones existing in the dotfiles files, and only their direct files are checked.
+    %s:
+      path (optional)                     A path under the user's dotfiles files directory or under ~/, in order to only diff part of the directories/files.
+
+  status                                  Prints a one line summary per out of sync file, without the diffs, and exits with an error if anything is out of sync.
+                                          Files are prefixed with "M" (modified), "A" (only in dotfiles), "?" (only in ~/ within tracked directories),
+                                          "T" (type changed, like a symlink replaced by a file) or "P" (only the file mode changed).
+    %s:
+      path (optional)                     A path under the user's dotfiles files directory or under ~/, in order to only check part of the directories/files.
 
   adopt                                   Adopts changes from ~/ files to user's dotfiles files.
                                           A subpath of users home directory can be provided as an argument, in order to only apply part of the directories/files.
                                           It can be a subdirectory or a file.
+                                          Files with the same content are skipped and reported as unchanged.
+                                          Symlinks are adopted as symlinks, with absolute targets inside ~/ mapped to the dotfiles files directory.
     %s:
       path (optional)                     A path under the user's home directory to adopt from.
 
+  add                                     Starts tracking a file or directory from ~/, copying it into the dotfiles files like adopt does.
+                                          New files are added into the base layer. It runs the adopt hooks and honors --dryRun.
+                                          The dotfiles files are the list of managed paths, so add refuses a path already in them,
+                                          use adopt to update it instead.
+    %s:
+      path                                A path under the user's home directory.
+
+  forget                                  Stops tracking a file or directory, removing it from the dotfiles files and the state file.
+                                          The ~/ copy is left untouched. It also honors --dryRun.
+    %s:
+      path                                A path under the user's dotfiles files directory or under ~/.
+
+  managed                                 Lists every tracked path in ~/ along with the layer it comes from.
+    %s:
+      path (optional)                     A path under the user's dotfiles files directory or under ~/, in order to only list part of the directories/files.
+
   apply                                   Apply changes from user's dotfiles files to ~/ files.
                                           A subpath of users dotfiles files directory can be provided as an argument, in order to only apply part of the directories/files.
                                           It can be a subdirectory or a file.
+                                          Files with the same content are skipped and reported as unchanged.
+                                          Symlinks are recreated as symlinks, with absolute targets inside the dotfiles files directory mapped to ~/.
+                                          Files that would be overwritten are backed up first to "$XDG_STATE_HOME/dots/backups/<timestamp>/",
+                                          it defaults to "~/.local/state/dots/backups/<timestamp>/". Files that would be created are recorded
+                                          in the same backup, so restore can remove them.
+                                          When applying the whole dotfiles files directory, pending scripts are run after the files.
     %s:
       path (optional)                     A path under the user's dotfiles files directory.
-`), color.MagentaString("dots"), color.MagentaString("dots"), color.GreenString("[OPTIONS]"), color.MagentaString("[COMMAND]"), color.YellowString("[ARGS]"), color.GreenString("Options"), color.MagentaString("Command"), color.YellowString("Args"), color.YellowString("Args"))
+
+  unmanaged                               Lists files in ~/ that are not in the dotfiles files, as candidates to add.
+                                          It walks "~/.config", "~/.local/bin" and the top-level dotfiles of ~/ by default, or "unmanagedRoots" in the config file.
+                                          Directories without any managed file are listed once, with a trailing "/", instead of every file in them.
+                                          Ignore rules are honored, and directories are walked up to --depth levels.
+    %s:
+      path (optional)                     A path under the user's home directory to walk instead of the roots.
+
+  git                                     Runs git in the dotfiles repository root (the parent of the dotfiles files directory).
+    %s:
+      args                                The arguments passed to git (e.g. "dots git log" or "dots git push").
+
+  commit                                  Stages every change in the dotfiles files and layers directories and commits it.
+    %s:
+      -m, --message <message> (optional)  The commit message, it defaults to "Update dotfiles".
+
+  sync                                    Pulls the dotfiles repository with "git pull --rebase", applies it, adopts the ~/ changes and commits them.
+                                          It aborts if the repository has uncommitted changes, the pull fails, or a file changed both in ~/
+                                          and in the dotfiles files with conflicting lines, or a file rendered from a template changed in ~/, all
+                                          before anything is applied. Files only changed in ~/ are adopted instead of applied.
+                                          Nothing is pushed, use "dots git push" for that.
+    %s:
+      -m, --message <message> (optional)  The commit message, it defaults to "Update dotfiles".
+
+  restore                                 Restores files from a backup made by apply back to ~/, and removes the files that apply created.
+                                          Without arguments it lists the available backups.
+    %s:
+      backup (optional)                   The backup to restore, or "latest" for the most recent one.
+      path (optional)                     A path under the user's home directory, in order to only restore part of the backup.
+
+  prune                                   Removes files from ~/ that were applied or adopted before but no longer exist in the dotfiles files.
+                                          Only files recorded in the state file are removed, and they are backed up first like on apply.
+                                          A file is only removed once the source it was recorded with is gone from the dotfiles repository,
+                                          so files from inactive profiles are kept, and files changed locally are kept with a warning.
+                                          It also honors --dryRun.
+    %s:
+      path (optional)                     A path under the user's dotfiles files directory or under ~/, in order to only prune part of the directories/files.
+
+  scripts                                 Lists the run once and run on change scripts, and if they are pending, changed or already ran.
+                                          With "reset" it forgets that scripts ran, so apply runs them again.
+    %s:
+      action (optional)                   "reset" to reset the scripts state.
+      name (optional)                     The script to reset, all scripts are reset if not provided.
+
+  check-ignore                            Checks if a path is ignored and prints the rule that matched it.
+                                          Ignore rules are read from ".dotsignore" files in the dotfiles files directory and its subdirectories,
+                                          using the gitignore syntax (including negation with "!" and "**"). They are honored by diff, status, adopt and apply.
+    %s:
+      path                                A path under the user's dotfiles files directory or under ~/.
+
+%s:
+  A "dots.toml" or "dots.yaml" file is looked up in the dotfiles repository root (the parent of the dotfiles files directory)
+  and then in "$XDG_CONFIG_HOME/dots/", it defaults to "~/.config/dots/".
+  Values are resolved in the following order: flags, env vars, config file and defaults.
+
+  filesDir                                Dotfiles files directory, relative to the config file directory.
+  targetDir                               Target directory, relative to the config file directory.
+  mode                                    Deployment mode, "copy" or "link".
+  ignore                                  List of extra ignore rules, using the ".dotsignore" syntax.
+  profiles                                List of profiles to activate.
+  identity                                Age identity file, relative to the config file directory.
+  recipients                              List of age recipients adopt encrypts ".age" files to.
+  mergeTool                               Command used to resolve merge conflicts, it can also be controled with "DOTS_MERGETOOL" env var.
+  unmanagedRoots                          List of directories walked by unmanaged, relative to ~/.
+  hooks                                   Hooks to run, see below.
+  vars                                    Variables available to templates as ".Vars".
+
+%s:
+  The dotfiles files directory is the base layer, other layers are looked up next to it, in the dotfiles repository root.
+  Files in later layers win over files in earlier ones, and adopt writes files back into the layer they came from.
+  New files are adopted into the base layer.
+
+  os/<os>/                                Applied automatically on the matching OS (e.g. "os/linux/" or "os/darwin/").
+  hosts/<hostname>/                       Applied automatically on the matching host.
+  profiles/<name>/                        Applied when the profile is activated, in the given order.
+
+%s:
+  Files ending with ".tmpl" are rendered with Go "text/template" on apply, and written to ~/ without the suffix.
+  Diff compares the rendered output against ~/, and adopt refuses to overwrite a template, showing the diff against the rendered output instead.
+
+  .Hostname, .OS, .Arch, .User, .Homedir  Built-in values of the current machine.
+  .Vars                                   Values of "vars" in the config file.
+  env "NAME"                              Looks up an env var.
+
+%s:
+  Files ending with ".age" are encrypted with age (https://age-encryption.org), so secrets can be kept in the dotfiles repository.
+  Apply decrypts them with the identity file and writes them to ~/ without the suffix, and diff decrypts them in memory.
+  Decrypted files are only readable by the user (at most 0600), and their mode in ~/ is not compared, so a chmod is kept.
+  Adopt encrypts the ~/ file back into the ".age" file, for all the recipients in "recipients" in the config file
+  and in ".dots/recipients" of the dotfiles repository root, one per line, so every machine can still decrypt it.
+  Without any, it falls back to the recipients of the identity file.
+  To start tracking a secret, encrypt it once with "age -e -a -i <identity> -o <path>.age <path>" into the dotfiles files directory.
+
+%s:
+  Hooks are shell commands run before and after apply and adopt, in the ~/ directory, and not run with --dryRun.
+  A failing pre hook aborts the command, failing post hooks are reported along with the files errors.
+  A hook can be scoped to a path under ~/, in order to only run when files under it are created or updated.
+  Hooks get "DOTS_HOOK", "DOTS_TARGET_DIR", "DOTS_DOTFILES_FILES_DIR" and "DOTS_CHANGED_FILES" (one path per line) env vars.
+
+  hooks.preApply, hooks.postApply         Lists of hooks in the config file, with "command" and an optional "path".
+  hooks.preAdopt, hooks.postAdopt
+  .dots/hooks/<stage>/                    Executable files in the dotfiles repository root, where stage is "pre-apply", "post-apply",
+                                          "pre-adopt" or "post-adopt". Files in subdirectories are scoped to that subdirectory
+                                          (e.g. ".dots/hooks/post-apply/.config/systemd/user/reload").
+
+%s:
+  Scripts are files in ".dots/scripts/" of the dotfiles repository root, run in the ~/ directory by apply,
+  after the files are applied and before post apply hooks. Scripts without the executable bit are run with "sh".
+  Unlike hooks, scripts run attached to the terminal, so they can prompt for input (e.g. sudo).
+  Which scripts ran is recorded in "$XDG_STATE_HOME/dots/scripts.json", it defaults to "~/.local/state/dots/scripts.json".
+
+  run_once_*                              Run once per machine.
+  run_onchange_*                          Run again every time its content changes.
+
+%s:
+  Apply and adopt record the source and destination hashes, the mode and the time of every file they write or find in sync
+  in "$XDG_STATE_HOME/dots/files.json", it defaults to "~/.local/state/dots/files.json".
+  Symlinks, as written in link mode, are recorded with their target instead of the hashes.
+  Diff and status use it to tell if a file changed locally, in the dotfiles files or both since then,
+  and apply warns before overwriting local changes.
+
+  Apply also records the content it writes to ~/ in "$XDG_STATE_HOME/dots/bases/", it defaults to "~/.local/state/dots/bases/".
+  When a file changed both in ~/ and in the dotfiles files since then, apply merges both changes instead of overwriting ~/,
+  and diff and status report it as a conflict. Changes to the same lines are written to ~/ with conflict markers,
+  to be resolved and adopted, unless a merge tool is set, which is run with "sh" and the "BASE", "LOCAL" (~/),
+  "REMOTE" (dotfiles files) and "MERGED" env vars pointing to files, where "MERGED" holds the result.
+  Apply skips a file that still has conflict markers, until they are resolved and the file is adopted.
+`), color.MagentaString("dots"), color.MagentaString("dots"), color.GreenString("[OPTIONS]"), color.MagentaString("[COMMAND]"), color.YellowString("[ARGS]"), color.GreenString("Options"), color.MagentaString("Command"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.YellowString("Args"), color.CyanString("Config"), color.CyanString("Layers"), color.CyanString("Templates"), color.CyanString("Secrets"), color.CyanString("Hooks"), color.CyanString("Scripts"), color.CyanString("State"))
 }
@@ -3,7 +3,7 @@ package displays
 import "github.com/m4rc3l05/dots/src/core"
 
 type IDisplays interface {
-	Environment(homedir string, dotfilesFilesDir string)
+	Environment(settings core.Settings)
 	Help()
 	Version(version string)
 }
@@ -7,21 +7,57 @@ import (
 )
 
 type SpyCommandsCalls struct {
-	Diff  []core.SpyCallNoRt
-	Adopt []core.SpyCallNoRt
-	Apply []core.SpyCallNoRt
+	Diff        []core.SpyCallNoRt
+	Status      []core.SpyCallNoRt
+	Adopt       []core.SpyCallNoRt
+	Apply       []core.SpyCallNoRt
+	Restore     []core.SpyCallNoRt
+	CheckIgnore []core.SpyCallNoRt
+	Scripts     []core.SpyCallNoRt
+	Prune       []core.SpyCallNoRt
+	Add         []core.SpyCallNoRt
+	Forget      []core.SpyCallNoRt
+	Managed     []core.SpyCallNoRt
+	Unmanaged   []core.SpyCallNoRt
+	Git         []core.SpyCallNoRt
+	Commit      []core.SpyCallNoRt
+	Sync        []core.SpyCallNoRt
 }
 
 type SpyCommandsCallNumber struct {
-	Diff  int
-	Adopt int
-	Apply int
+	Diff        int
+	Status      int
+	Adopt       int
+	Apply       int
+	Restore     int
+	CheckIgnore int
+	Scripts     int
+	Prune       int
+	Add         int
+	Forget      int
+	Managed     int
+	Unmanaged   int
+	Git         int
+	Commit      int
+	Sync        int
 }
 
 type SpyCommandsImpl struct {
-	Adopt func(args commands.AdoptArgs) (bool, error)
-	Diff  func(args commands.DiffArgs) (bool, error)
-	Apply func(args commands.ApplyArgs) (bool, error)
+	Adopt       func(args commands.AdoptArgs) (bool, error)
+	Diff        func(args commands.DiffArgs) (bool, error)
+	Status      func(args commands.StatusArgs) (bool, error)
+	Apply       func(args commands.ApplyArgs) (bool, error)
+	Restore     func(args commands.RestoreArgs) (bool, error)
+	CheckIgnore func(args commands.CheckIgnoreArgs) (bool, error)
+	Scripts     func(args commands.ScriptsArgs) (bool, error)
+	Prune       func(args commands.PruneArgs) (bool, error)
+	Add         func(args commands.AddArgs) (bool, error)
+	Forget      func(args commands.ForgetArgs) (bool, error)
+	Managed     func(args commands.ManagedArgs) (bool, error)
+	Unmanaged   func(args commands.UnmanagedArgs) (bool, error)
+	Git         func(args commands.GitArgs) (bool, error)
+	Commit      func(args commands.CommitArgs) (bool, error)
+	Sync        func(args commands.SyncArgs) (bool, error)
 }
 
 type SpyCommands struct {
@@ -41,6 +77,16 @@ func (sl *SpyCommands) Diff(args commands.DiffArgs) (bool, error) {
 	return true, nil
 }
 
+func (sl *SpyCommands) Status(args commands.StatusArgs) (bool, error) {
+	sl.Calls.Status = append(sl.Calls.Status, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Status != nil {
+		return sl.Impl.Status(args)
+	}
+
+	return true, nil
+}
+
 func (sl *SpyCommands) Adopt(args commands.AdoptArgs) (bool, error) {
 	sl.Calls.Adopt = append(sl.Calls.Adopt, core.SpyCallNoRt{Args: []any{args}})
 
@@ -61,6 +107,116 @@ func (sl *SpyCommands) Apply(args commands.ApplyArgs) (bool, error) {
 	return true, nil
 }
 
+func (sl *SpyCommands) Restore(args commands.RestoreArgs) (bool, error) {
+	sl.Calls.Restore = append(sl.Calls.Restore, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Restore != nil {
+		return sl.Impl.Restore(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) CheckIgnore(args commands.CheckIgnoreArgs) (bool, error) {
+	sl.Calls.CheckIgnore = append(sl.Calls.CheckIgnore, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.CheckIgnore != nil {
+		return sl.Impl.CheckIgnore(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Scripts(args commands.ScriptsArgs) (bool, error) {
+	sl.Calls.Scripts = append(sl.Calls.Scripts, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Scripts != nil {
+		return sl.Impl.Scripts(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Prune(args commands.PruneArgs) (bool, error) {
+	sl.Calls.Prune = append(sl.Calls.Prune, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Prune != nil {
+		return sl.Impl.Prune(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Add(args commands.AddArgs) (bool, error) {
+	sl.Calls.Add = append(sl.Calls.Add, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Add != nil {
+		return sl.Impl.Add(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Forget(args commands.ForgetArgs) (bool, error) {
+	sl.Calls.Forget = append(sl.Calls.Forget, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Forget != nil {
+		return sl.Impl.Forget(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Managed(args commands.ManagedArgs) (bool, error) {
+	sl.Calls.Managed = append(sl.Calls.Managed, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Managed != nil {
+		return sl.Impl.Managed(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Unmanaged(args commands.UnmanagedArgs) (bool, error) {
+	sl.Calls.Unmanaged = append(sl.Calls.Unmanaged, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Unmanaged != nil {
+		return sl.Impl.Unmanaged(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Git(args commands.GitArgs) (bool, error) {
+	sl.Calls.Git = append(sl.Calls.Git, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Git != nil {
+		return sl.Impl.Git(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Commit(args commands.CommitArgs) (bool, error) {
+	sl.Calls.Commit = append(sl.Calls.Commit, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Commit != nil {
+		return sl.Impl.Commit(args)
+	}
+
+	return true, nil
+}
+
+func (sl *SpyCommands) Sync(args commands.SyncArgs) (bool, error) {
+	sl.Calls.Sync = append(sl.Calls.Sync, core.SpyCallNoRt{Args: []any{args}})
+
+	if sl.Impl.Sync != nil {
+		return sl.Impl.Sync(args)
+	}
+
+	return true, nil
+}
+
 func MakeSpyCommands() *SpyCommands {
 	return &SpyCommands{}
 }
@@ -75,6 +231,18 @@ func AssertSpyCommandsCalls(Command SpyCommands, callNumber *SpyCommandsCallNumb
 	}
 
 	gomega.Expect(Command.Calls.Diff).To(gomega.HaveLen(callNumberVal.Diff))
+	gomega.Expect(Command.Calls.Status).To(gomega.HaveLen(callNumberVal.Status))
 	gomega.Expect(Command.Calls.Adopt).To(gomega.HaveLen(callNumberVal.Adopt))
 	gomega.Expect(Command.Calls.Apply).To(gomega.HaveLen(callNumberVal.Apply))
+	gomega.Expect(Command.Calls.Restore).To(gomega.HaveLen(callNumberVal.Restore))
+	gomega.Expect(Command.Calls.CheckIgnore).To(gomega.HaveLen(callNumberVal.CheckIgnore))
+	gomega.Expect(Command.Calls.Scripts).To(gomega.HaveLen(callNumberVal.Scripts))
+	gomega.Expect(Command.Calls.Prune).To(gomega.HaveLen(callNumberVal.Prune))
+	gomega.Expect(Command.Calls.Add).To(gomega.HaveLen(callNumberVal.Add))
+	gomega.Expect(Command.Calls.Forget).To(gomega.HaveLen(callNumberVal.Forget))
+	gomega.Expect(Command.Calls.Managed).To(gomega.HaveLen(callNumberVal.Managed))
+	gomega.Expect(Command.Calls.Unmanaged).To(gomega.HaveLen(callNumberVal.Unmanaged))
+	gomega.Expect(Command.Calls.Git).To(gomega.HaveLen(callNumberVal.Git))
+	gomega.Expect(Command.Calls.Commit).To(gomega.HaveLen(callNumberVal.Commit))
+	gomega.Expect(Command.Calls.Sync).To(gomega.HaveLen(callNumberVal.Sync))
 }
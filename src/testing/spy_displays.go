@@ -23,10 +23,10 @@ type SpyDisplays struct {
 	Calls SpyDisplaysCalls
 }
 
-func (sd *SpyDisplays) Environment(homedir string, dotfilesFilesDir string) {
+func (sd *SpyDisplays) Environment(settings core.Settings) {
 	sd.Calls.Environment = append(
 		sd.Calls.Environment,
-		core.SpyCallNoRt{Args: []any{homedir, dotfilesFilesDir}},
+		core.SpyCallNoRt{Args: []any{settings}},
 	)
 }
 
@@ -0,0 +1,26 @@
+package testing
+
+import (
+	"github.com/m4rc3l05/dots/src/core"
+)
+
+type SpyReporter struct {
+	core.IReporter
+
+	Records []core.Record
+	Flushes int
+}
+
+func (sr *SpyReporter) Report(record core.Record) {
+	sr.Records = append(sr.Records, record)
+}
+
+func (sr *SpyReporter) Flush() error {
+	sr.Flushes += 1
+
+	return nil
+}
+
+func MakeSpyReporter() *SpyReporter {
+	return &SpyReporter{}
+}